@@ -1,8 +1,11 @@
 package auth
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -13,6 +16,9 @@ type Token struct {
 	AccessToken string
 	InstanceUrl string
 	Expiration  time.Time
+	// refresh token the access token was issued with, if any. kept with the
+	// token so a process loading it from a TokenStore can refresh it too.
+	RefreshToken string
 }
 
 // individual implementations of an AuthFlow are expected to handle managing
@@ -38,7 +44,7 @@ type TokenResponse struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 	InstanceUrl  string `json:"instance_url"`
-	IssuedAt     int64  `json:"issued_at"`
+	IssuedAt     int64  `json:"issued_at,string"`
 	Id           string `json:"id"`
 }
 
@@ -54,3 +60,91 @@ func (err AuthError) Error() string {
 		err.ErrorDescription,
 	)
 }
+
+// posts a grant to a token endpoint and decodes the response. shared by the
+// flows so each only has to build its own payload.
+func requestToken(
+	httpClient *http.Client,
+	tokenEndpoint string,
+	payload url.Values,
+) (TokenResponse, error) {
+	res, err := httpClient.PostForm(
+		tokenEndpoint,
+		payload,
+	)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 200 {
+		var tokenResponse TokenResponse
+		decodeError := json.NewDecoder(res.Body).Decode(&tokenResponse)
+		if decodeError != nil {
+			return TokenResponse{}, errors.Join(
+				AuthError{
+					ErrorCode:        "DECODING_ERROR",
+					ErrorDescription: "error decoding sfdc auth response",
+				},
+				decodeError,
+			)
+		}
+		return tokenResponse, nil
+	}
+	var errorResponse AuthError
+	decodeError := json.NewDecoder(res.Body).Decode(&errorResponse)
+	if decodeError != nil {
+		return TokenResponse{}, errors.Join(
+			AuthError{
+				ErrorCode:        "DECODING_ERROR",
+				ErrorDescription: "error decoding sfdc auth response",
+			},
+			decodeError,
+		)
+	}
+	return TokenResponse{}, errorResponse
+}
+
+// exchanges a refresh token for a new access token. client secret is optional
+// for connected apps that do not require it on refresh.
+func refreshTokenGrant(
+	httpClient *http.Client,
+	tokenEndpoint string,
+	clientId string,
+	clientSecret string,
+	refreshToken string,
+) (TokenResponse, error) {
+	if len(refreshToken) == 0 {
+		return TokenResponse{}, ErrNoRefreshToken
+	}
+	payload := make(url.Values)
+	payload["grant_type"] = []string{"refresh_token"}
+	payload["client_id"] = []string{clientId}
+	if len(clientSecret) > 0 {
+		payload["client_secret"] = []string{clientSecret}
+	}
+	payload["refresh_token"] = []string{refreshToken}
+
+	return requestToken(
+		httpClient,
+		tokenEndpoint,
+		payload,
+	)
+}
+
+// converts a token response into a Token. salesforce does not return the
+// session lifetime so expiration is assumed to be an hour after issue.
+func (tokenResponse TokenResponse) asToken() Token {
+	expiration := time.UnixMilli(tokenResponse.IssuedAt).Add(
+		time.Hour,
+	)
+	return Token{
+		AccessToken:  tokenResponse.AccessToken,
+		InstanceUrl:  tokenResponse.InstanceUrl,
+		Expiration:   expiration,
+		Id:           tokenResponse.Id,
+		RefreshToken: tokenResponse.RefreshToken,
+	}
+}
+
+var ErrNoRefreshToken = errors.New("no refresh token available")
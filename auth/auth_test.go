@@ -1,9 +1,12 @@
 package auth
 
 import (
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestClientCredentials(t *testing.T) {
@@ -38,3 +41,45 @@ func TestClientCredentials(t *testing.T) {
 		)
 	}
 }
+
+// salesforce sends issued_at as a string of milliseconds. it used to be
+// decoded as a number, which failed silently and left every token expired.
+func TestIssuedAtExpiration(t *testing.T) {
+	issuedAt := time.Now().Truncate(time.Millisecond)
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(
+				w,
+				`{"access_token":"accessToken","instance_url":"https://example.my.salesforce.com","issued_at":"%d"}`,
+				issuedAt.UnixMilli(),
+			)
+		},
+	))
+	defer server.Close()
+
+	flows := []AuthFlow{
+		ClientCredentialsFlow{
+			ClientId:      "clientId",
+			ClientSecret:  "clientSecret",
+			TokenEndpoint: server.URL,
+		},
+		UsernamePasswordFlow{
+			ClientId:      "clientId",
+			ClientSecret:  "clientSecret",
+			Username:      "user@example.com",
+			Password:      "password",
+			TokenEndpoint: server.URL,
+		},
+	}
+
+	expected := issuedAt.Add(time.Hour)
+	for _, authFlow := range flows {
+		token, err := authFlow.NewToken(server.Client())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !token.Expiration.Equal(expected) {
+			t.Errorf("%T: expected %v, actual %v", authFlow, expected, token.Expiration)
+		}
+	}
+}
@@ -1,17 +1,17 @@
 package auth
 
 import (
-	"encoding/json"
-	"errors"
 	"net/http"
 	"net/url"
-	"time"
 )
 
 type ClientCredentialsFlow struct {
 	ClientId      string
 	ClientSecret  string
 	TokenEndpoint string
+	// when set the token expiration is read from the introspection endpoint
+	// instead of being assumed to be an hour after issue.
+	IntrospectExpiration bool
 }
 
 func (flow ClientCredentialsFlow) NewToken(
@@ -22,41 +22,26 @@ func (flow ClientCredentialsFlow) NewToken(
 	payload["client_secret"] = []string{flow.ClientSecret}
 	payload["grant_type"] = []string{"client_credentials"}
 
-	res, err := httpClient.PostForm(
+	tokenResponse, err := requestToken(
+		httpClient,
 		flow.TokenEndpoint,
 		payload,
 	)
 	if err != nil {
 		return Token{}, err
 	}
-	defer res.Body.Close()
+	token := tokenResponse.asToken()
 
-	if res.StatusCode == 200 {
-		var tokenResponse TokenResponse
-		json.NewDecoder(res.Body).Decode(&tokenResponse)
-
-		expiration := time.UnixMilli(tokenResponse.IssuedAt).Add(
-			time.Hour,
-		)
-		return Token{
-			AccessToken: tokenResponse.AccessToken,
-			InstanceUrl: tokenResponse.InstanceUrl,
-			Expiration:  expiration,
-			Id:          tokenResponse.Id,
-		}, nil
-	}
-	var errorResponse AuthError
-	decodeError := json.NewDecoder(res.Body).Decode(&errorResponse)
-	if decodeError != nil {
-		return Token{}, errors.Join(
-			AuthError{
-				ErrorCode:        "DECODING_ERROR",
-				ErrorDescription: "error decoding sfdc auth response",
-			},
-			decodeError,
+	if flow.IntrospectExpiration {
+		return introspectExpiration(
+			httpClient,
+			flow.TokenEndpoint,
+			flow.ClientId,
+			flow.ClientSecret,
+			token,
 		)
 	}
-	return Token{}, errorResponse
+	return token, nil
 }
 func (flow ClientCredentialsFlow) RefreshToken(
 	httpClient *http.Client,
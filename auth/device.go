@@ -0,0 +1,282 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DeviceAuthorization is returned when a device flow is started. the user has
+// to visit VerificationUri and enter UserCode to grant access.
+type DeviceAuthorization struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationUri string `json:"verification_uri"`
+	Interval        int    `json:"interval"`
+	ExpiresIn       int    `json:"expires_in"`
+}
+
+// DeviceFlow implements the oauth 2.0 device flow for headless logins. NewToken
+// requests a user code, passes it to OnUserCode and polls the token endpoint
+// until the user approves or denies the request. the refresh token issued on
+// approval is kept by the flow and used by RefreshToken, so the flow must be
+// used as a pointer.
+type DeviceFlow struct {
+	ClientId      string
+	ClientSecret  string
+	Scopes        []string
+	TokenEndpoint string
+	// called once the user code is available, typically to print the
+	// verification url. returning an error aborts the flow.
+	OnUserCode func(DeviceAuthorization) error
+	// revoke only the access token on Revoke, leaving the refresh token
+	// and every session issued from it usable.
+	KeepRefreshToken bool
+
+	mu           sync.Mutex
+	refreshToken string
+	// overridden in tests
+	sleep func(time.Duration)
+}
+
+// slow_down responses increase the polling interval by this amount (rfc 8628).
+const deviceSlowDownIncrement = 5 * time.Second
+
+// how long a device code is polled for when the server does not send
+// expires_in, salesforce device codes last 10 minutes.
+const deviceDefaultExpiration = 10 * time.Minute
+
+func (flow *DeviceFlow) NewToken(
+	httpClient *http.Client,
+) (Token, error) {
+	return flow.NewTokenContext(
+		context.Background(),
+		httpClient,
+	)
+}
+
+// like NewToken, waiting for the user stops once ctx is done. the flow is not
+// locked while waiting so GetRefreshToken and RefreshToken do not block.
+func (flow *DeviceFlow) NewTokenContext(
+	ctx context.Context,
+	httpClient *http.Client,
+) (Token, error) {
+	flow.mu.Lock()
+	if len(flow.refreshToken) > 0 {
+		defer flow.mu.Unlock()
+		return flow.refresh(httpClient)
+	}
+	flow.mu.Unlock()
+
+	authorization, err := flow.authorize(httpClient)
+	if err != nil {
+		return Token{}, err
+	}
+	if flow.OnUserCode != nil {
+		err = flow.OnUserCode(authorization)
+		if err != nil {
+			return Token{}, err
+		}
+	}
+
+	tokenResponse, err := flow.poll(
+		ctx,
+		httpClient,
+		authorization,
+	)
+	if err != nil {
+		return Token{}, err
+	}
+
+	flow.mu.Lock()
+	flow.refreshToken = tokenResponse.RefreshToken
+	flow.mu.Unlock()
+
+	return tokenResponse.asToken(), nil
+}
+func (flow *DeviceFlow) RefreshToken(
+	httpClient *http.Client,
+) (Token, error) {
+	flow.mu.Lock()
+	defer flow.mu.Unlock()
+
+	return flow.refresh(httpClient)
+}
+
+// replaces the refresh token, such as with one loaded from a token store, so
+// the flow refreshes instead of starting a new login.
+func (flow *DeviceFlow) SetRefreshToken(
+	refreshToken string,
+) {
+	flow.mu.Lock()
+	defer flow.mu.Unlock()
+
+	flow.refreshToken = refreshToken
+}
+
+// returns the refresh token obtained from the device flow so it can be
+// persisted by the caller.
+func (flow *DeviceFlow) GetRefreshToken() string {
+	flow.mu.Lock()
+	defer flow.mu.Unlock()
+
+	return flow.refreshToken
+}
+
+// must be called with mu held.
+func (flow *DeviceFlow) refresh(
+	httpClient *http.Client,
+) (Token, error) {
+	tokenResponse, err := refreshTokenGrant(
+		httpClient,
+		flow.TokenEndpoint,
+		flow.ClientId,
+		flow.ClientSecret,
+		flow.refreshToken,
+	)
+	if err != nil {
+		return Token{}, err
+	}
+	if len(tokenResponse.RefreshToken) > 0 {
+		flow.refreshToken = tokenResponse.RefreshToken
+	}
+	token := tokenResponse.asToken()
+	token.RefreshToken = flow.refreshToken
+	return token, nil
+}
+
+func (flow *DeviceFlow) authorize(
+	httpClient *http.Client,
+) (DeviceAuthorization, error) {
+	payload := make(url.Values)
+	payload["response_type"] = []string{"device_code"}
+	payload["client_id"] = []string{flow.ClientId}
+	if len(flow.Scopes) > 0 {
+		payload["scope"] = []string{strings.Join(flow.Scopes, " ")}
+	}
+
+	res, err := httpClient.PostForm(
+		flow.TokenEndpoint,
+		payload,
+	)
+	if err != nil {
+		return DeviceAuthorization{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 200 {
+		var authorization DeviceAuthorization
+		decodeError := json.NewDecoder(res.Body).Decode(&authorization)
+		if decodeError != nil {
+			return DeviceAuthorization{}, errors.Join(
+				AuthError{
+					ErrorCode:        "DECODING_ERROR",
+					ErrorDescription: "error decoding sfdc device authorization",
+				},
+				decodeError,
+			)
+		}
+		return authorization, nil
+	}
+	var errorResponse AuthError
+	decodeError := json.NewDecoder(res.Body).Decode(&errorResponse)
+	if decodeError != nil {
+		return DeviceAuthorization{}, errors.Join(
+			AuthError{
+				ErrorCode:        "DECODING_ERROR",
+				ErrorDescription: "error decoding sfdc auth response",
+			},
+			decodeError,
+		)
+	}
+	return DeviceAuthorization{}, errorResponse
+}
+
+// polls the token endpoint at the requested interval until the user acts on
+// the request, the device code expires or ctx is done.
+func (flow *DeviceFlow) poll(
+	ctx context.Context,
+	httpClient *http.Client,
+	authorization DeviceAuthorization,
+) (TokenResponse, error) {
+	interval := time.Duration(authorization.Interval) * time.Second
+	if interval <= 0 {
+		interval = deviceSlowDownIncrement
+	}
+	expiresIn := time.Duration(authorization.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = deviceDefaultExpiration
+	}
+	deadline := time.Now().Add(expiresIn)
+
+	payload := make(url.Values)
+	payload["grant_type"] = []string{"device"}
+	payload["client_id"] = []string{flow.ClientId}
+	if len(flow.ClientSecret) > 0 {
+		payload["client_secret"] = []string{flow.ClientSecret}
+	}
+	payload["code"] = []string{authorization.DeviceCode}
+
+	for {
+		if time.Now().After(deadline) {
+			return TokenResponse{}, ErrDeviceCodeExpired
+		}
+		err := flow.wait(ctx, interval)
+		if err != nil {
+			return TokenResponse{}, err
+		}
+
+		tokenResponse, err := requestToken(
+			httpClient,
+			flow.TokenEndpoint,
+			payload,
+		)
+		if err == nil {
+			return tokenResponse, nil
+		}
+
+		var authError AuthError
+		if !errors.As(err, &authError) {
+			return TokenResponse{}, err
+		}
+		switch authError.ErrorCode {
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			interval += deviceSlowDownIncrement
+			continue
+		case "expired_token":
+			return TokenResponse{}, errors.Join(
+				ErrDeviceCodeExpired,
+				err,
+			)
+		default:
+			return TokenResponse{}, err
+		}
+	}
+}
+
+func (flow *DeviceFlow) wait(
+	ctx context.Context,
+	d time.Duration,
+) error {
+	if flow.sleep != nil {
+		flow.sleep(d)
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+var ErrDeviceCodeExpired = errors.New("device code expired")
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeviceFlow(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			if r.Form.Get("response_type") == "device_code" {
+				w.Write([]byte(`{
+					"device_code": "deviceCode",
+					"user_code": "ABCD1234",
+					"verification_uri": "https://login.salesforce.com/setup/connect",
+					"interval": 5
+				}`))
+				return
+			}
+			if r.Form.Get("grant_type") != "device" ||
+				r.Form.Get("code") != "deviceCode" {
+				t.Errorf("unexpected form: %v", r.Form)
+			}
+			polls++
+			switch polls {
+			case 1:
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"authorization_pending","error_description":"pending"}`))
+			case 2:
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"slow_down","error_description":"slow down"}`))
+			default:
+				w.Write([]byte(`{
+					"access_token": "accessToken",
+					"refresh_token": "refreshToken",
+					"issued_at": "1700000000000"
+				}`))
+			}
+		},
+	))
+	defer server.Close()
+
+	var intervals []time.Duration
+	var userCode string
+	authFlow := DeviceFlow{
+		ClientId:      "clientId",
+		TokenEndpoint: server.URL,
+		OnUserCode: func(authorization DeviceAuthorization) error {
+			userCode = authorization.UserCode
+			return nil
+		},
+		sleep: func(d time.Duration) {
+			intervals = append(intervals, d)
+		},
+	}
+
+	token, err := authFlow.NewToken(server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "accessToken" {
+		t.Fatalf("unexpected access token %v", token.AccessToken)
+	}
+	if userCode != "ABCD1234" {
+		t.Fatalf("unexpected user code %v", userCode)
+	}
+	if authFlow.GetRefreshToken() != "refreshToken" {
+		t.Fatalf("unexpected refresh token %v", authFlow.GetRefreshToken())
+	}
+
+	expected := []time.Duration{5 * time.Second, 5 * time.Second, 10 * time.Second}
+	if len(intervals) != len(expected) {
+		t.Fatalf("expected %v, actual %v", expected, intervals)
+	}
+	for i := range expected {
+		if intervals[i] != expected[i] {
+			t.Fatalf("expected %v, actual %v", expected, intervals)
+		}
+	}
+}
+
+func TestDeviceFlowDenied(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			if r.Form.Get("response_type") == "device_code" {
+				w.Write([]byte(`{"device_code":"deviceCode","user_code":"ABCD1234","interval":1}`))
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"access_denied","error_description":"end-user denied authorization"}`))
+		},
+	))
+	defer server.Close()
+
+	authFlow := DeviceFlow{
+		ClientId:      "clientId",
+		TokenEndpoint: server.URL,
+		sleep:         func(time.Duration) {},
+	}
+
+	_, err := authFlow.NewToken(server.Client())
+	authError, ok := err.(AuthError)
+	if !ok || authError.ErrorCode != "access_denied" {
+		t.Fatalf("expected access_denied, received %v", err)
+	}
+}
+
+func TestDeviceFlowCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			if r.Form.Get("response_type") == "device_code" {
+				w.Write([]byte(`{"device_code":"deviceCode","user_code":"ABCD1234","interval":60}`))
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"authorization_pending","error_description":"pending"}`))
+		},
+	))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	authFlow := &DeviceFlow{
+		ClientId:      "clientId",
+		TokenEndpoint: server.URL,
+		OnUserCode: func(DeviceAuthorization) error {
+			cancel()
+			return nil
+		},
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := authFlow.NewTokenContext(ctx, server.Client())
+		done <- err
+	}()
+
+	// the flow is not locked while waiting on the user
+	authFlow.GetRefreshToken()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %v, actual %v", context.Canceled, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected polling to stop once the context is done")
+	}
+}
@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const ProductionIntrospectionEndpoint string = "https://login.salesforce.com/services/oauth2/introspect"
+const SandboxIntrospectionEndpoint string = "https://test.salesforce.com/services/oauth2/introspect"
+
+// token type hints accepted by Introspect.
+const AccessTokenHint string = "access_token"
+const RefreshTokenHint string = "refresh_token"
+
+// response of the token introspection endpoint. Exp, Iat and Nbf are unix
+// seconds and are zero for inactive tokens.
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope"`
+	ClientId  string `json:"client_id"`
+	Username  string `json:"username"`
+	Subject   string `json:"sub"`
+	TokenType string `json:"token_type"`
+	Exp       int64  `json:"exp"`
+	Iat       int64  `json:"iat"`
+	Nbf       int64  `json:"nbf"`
+}
+
+// expiration of the token as reported by salesforce.
+func (introspection IntrospectionResponse) Expiration() time.Time {
+	return time.Unix(introspection.Exp, 0)
+}
+
+// queries the introspection endpoint for an access or refresh token.
+// tokenTypeHint is AccessTokenHint or RefreshTokenHint, or empty to let
+// salesforce work it out. the connected app must have introspection enabled
+// for the calling client.
+func Introspect(
+	httpClient *http.Client,
+	introspectionEndpoint string,
+	clientId string,
+	clientSecret string,
+	token string,
+	tokenTypeHint string,
+) (IntrospectionResponse, error) {
+	payload := make(url.Values)
+	payload["token"] = []string{token}
+	if len(tokenTypeHint) > 0 {
+		payload["token_type_hint"] = []string{tokenTypeHint}
+	}
+	payload["client_id"] = []string{clientId}
+	payload["client_secret"] = []string{clientSecret}
+
+	res, err := httpClient.PostForm(
+		introspectionEndpoint,
+		payload,
+	)
+	if err != nil {
+		return IntrospectionResponse{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 200 {
+		var introspection IntrospectionResponse
+		decodeError := json.NewDecoder(res.Body).Decode(&introspection)
+		if decodeError != nil {
+			return IntrospectionResponse{}, errors.Join(
+				AuthError{
+					ErrorCode:        "DECODING_ERROR",
+					ErrorDescription: "error decoding sfdc introspection response",
+				},
+				decodeError,
+			)
+		}
+		return introspection, nil
+	}
+	var errorResponse AuthError
+	decodeError := json.NewDecoder(res.Body).Decode(&errorResponse)
+	if decodeError != nil {
+		return IntrospectionResponse{}, errors.Join(
+			AuthError{
+				ErrorCode:        "DECODING_ERROR",
+				ErrorDescription: "error decoding sfdc auth response",
+			},
+			decodeError,
+		)
+	}
+	return IntrospectionResponse{}, errorResponse
+}
+
+// derives the introspection endpoint from a token endpoint on the same host,
+// e.g. https://login.salesforce.com/services/oauth2/token.
+func IntrospectionEndpoint(
+	tokenEndpoint string,
+) string {
+	return strings.TrimSuffix(tokenEndpoint, "/token") + "/introspect"
+}
+
+// replaces the guessed expiration of a token with the one reported by the
+// introspection endpoint.
+func introspectExpiration(
+	httpClient *http.Client,
+	tokenEndpoint string,
+	clientId string,
+	clientSecret string,
+	token Token,
+) (Token, error) {
+	introspection, err := Introspect(
+		httpClient,
+		IntrospectionEndpoint(tokenEndpoint),
+		clientId,
+		clientSecret,
+		token.AccessToken,
+		AccessTokenHint,
+	)
+	if err != nil {
+		return Token{}, errors.Join(
+			ErrIntrospection,
+			err,
+		)
+	}
+	if !introspection.Active || introspection.Exp == 0 {
+		return Token{}, ErrIntrospection
+	}
+	token.Expiration = introspection.Expiration()
+
+	return token, nil
+}
+
+var ErrIntrospection = errors.New("error introspecting token")
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIntrospectExpiration(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(
+		"/services/oauth2/token",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{
+				"access_token": "accessToken",
+				"instance_url": "https://example.my.salesforce.com",
+				"issued_at": "1700000000000"
+			}`))
+		},
+	)
+	mux.HandleFunc(
+		"/services/oauth2/introspect",
+		func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			if r.Form.Get("token") != "accessToken" ||
+				r.Form.Get("client_id") != "clientId" {
+				t.Errorf("unexpected form: %v", r.Form)
+			}
+			w.Write([]byte(`{
+				"active": true,
+				"scope": "api",
+				"exp": 1700007200,
+				"iat": 1700000000
+			}`))
+		},
+	)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	authFlow := ClientCredentialsFlow{
+		ClientId:             "clientId",
+		ClientSecret:         "clientSecret",
+		TokenEndpoint:        server.URL + "/services/oauth2/token",
+		IntrospectExpiration: true,
+	}
+
+	token, err := authFlow.NewToken(server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := time.Unix(1700007200, 0)
+	if !token.Expiration.Equal(expected) {
+		t.Fatalf(
+			"expected %v, actual %v",
+			expected,
+			token.Expiration,
+		)
+	}
+}
+
+func TestIntrospectionEndpoint(t *testing.T) {
+	expected := ProductionIntrospectionEndpoint
+	actual := IntrospectionEndpoint(ProductionTokenEndpoint)
+
+	if expected != actual {
+		t.Fatalf(
+			"expected %v, actual %v",
+			expected,
+			actual,
+		)
+	}
+}
+
+func TestIntrospectHint(t *testing.T) {
+	var hints []string
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			hints = append(hints, r.Form.Get("token_type_hint"))
+			w.Write([]byte(`{"active": true}`))
+		},
+	))
+	defer server.Close()
+
+	for _, hint := range []string{RefreshTokenHint, ""} {
+		_, err := Introspect(
+			server.Client(),
+			server.URL,
+			"clientId",
+			"clientSecret",
+			"refreshToken",
+			hint,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{RefreshTokenHint, ""}
+	for i := range expected {
+		if hints[i] != expected[i] {
+			t.Errorf("expected %v, actual %v", expected, hints)
+		}
+	}
+}
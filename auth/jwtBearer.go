@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// audiences accepted by salesforce for the jwt bearer flow. experience cloud
+// sites use the site url instead.
+const ProductionAudience string = "https://login.salesforce.com"
+const SandboxAudience string = "https://test.salesforce.com"
+
+const jwtBearerGrantType string = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+
+// JWTBearerFlow signs an RS256 assertion with the private key matching the
+// certificate uploaded to the connected app and exchanges it for a token.
+// either PrivateKey (pem encoded pkcs1 or pkcs8) or Signer must be provided,
+// Signer takes precedence when both are set and must hold an rsa key.
+type JWTBearerFlow struct {
+	ClientId      string
+	Username      string
+	Audience      string
+	PrivateKey    []byte
+	Signer        crypto.Signer
+	TokenEndpoint string
+	// lifetime of the assertion, defaults to 3 minutes which is the maximum
+	// salesforce allows.
+	AssertionLifetime time.Duration
+}
+
+func (flow JWTBearerFlow) NewToken(
+	httpClient *http.Client,
+) (Token, error) {
+	assertion, err := flow.assertion(time.Now())
+	if err != nil {
+		return Token{}, err
+	}
+
+	payload := make(url.Values)
+	payload["grant_type"] = []string{jwtBearerGrantType}
+	payload["assertion"] = []string{assertion}
+
+	tokenResponse, err := requestToken(
+		httpClient,
+		flow.TokenEndpoint,
+		payload,
+	)
+	if err != nil {
+		return Token{}, err
+	}
+	return tokenResponse.asToken(), nil
+}
+func (flow JWTBearerFlow) RefreshToken(
+	httpClient *http.Client,
+) (Token, error) {
+	return flow.NewToken(httpClient)
+}
+
+type jwtClaims struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`
+	Audience  string `json:"aud"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// builds the signed assertion: base64url(header).base64url(claims).signature
+func (flow JWTBearerFlow) assertion(
+	now time.Time,
+) (string, error) {
+	signer, err := flow.signer()
+	if err != nil {
+		return "", err
+	}
+
+	audience := flow.Audience
+	if len(audience) == 0 {
+		audience = ProductionAudience
+	}
+	lifetime := flow.AssertionLifetime
+	if lifetime == 0 {
+		lifetime = 3 * time.Minute
+	}
+
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(jwtClaims{
+		Issuer:    flow.ClientId,
+		Subject:   flow.Username,
+		Audience:  audience,
+		ExpiresAt: now.Add(lifetime).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoding := base64.RawURLEncoding
+	signingInput := encoding.EncodeToString(header) +
+		"." +
+		encoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := signer.Sign(
+		rand.Reader,
+		digest[:],
+		crypto.SHA256,
+	)
+	if err != nil {
+		return "", errors.Join(
+			ErrJWTSigning,
+			err,
+		)
+	}
+
+	return signingInput + "." + encoding.EncodeToString(signature), nil
+}
+
+func (flow JWTBearerFlow) signer() (crypto.Signer, error) {
+	if flow.Signer != nil {
+		// the assertion is always RS256, salesforce rejects other algorithms
+		if _, ok := flow.Signer.Public().(*rsa.PublicKey); !ok {
+			return nil, ErrPrivateKey
+		}
+		return flow.Signer, nil
+	}
+	if len(flow.PrivateKey) == 0 {
+		return nil, ErrPrivateKey
+	}
+	return ParseRSAPrivateKey(flow.PrivateKey)
+}
+
+// parses a pem encoded rsa private key in either pkcs1 ("RSA PRIVATE KEY") or
+// pkcs8 ("PRIVATE KEY") form.
+func ParseRSAPrivateKey(
+	pemBytes []byte,
+) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, ErrPrivateKey
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Join(
+			ErrPrivateKey,
+			err,
+		)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, ErrPrivateKey
+	}
+	return rsaKey, nil
+}
+
+var ErrPrivateKey = errors.New("invalid rsa private key")
+var ErrJWTSigning = errors.New("error signing jwt assertion")
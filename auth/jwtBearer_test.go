@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJWTBearerFlow(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			if r.Form.Get("grant_type") != jwtBearerGrantType {
+				t.Errorf(
+					"unexpected grant_type: %s",
+					r.Form.Get("grant_type"),
+				)
+			}
+
+			parts := strings.Split(r.Form.Get("assertion"), ".")
+			if len(parts) != 3 {
+				t.Errorf("expected 3 jwt segments, received %d", len(parts))
+				return
+			}
+			signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+			if err != nil {
+				t.Errorf("invalid signature encoding: %v", err)
+				return
+			}
+			digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+			err = rsa.VerifyPKCS1v15(
+				&key.PublicKey,
+				crypto.SHA256,
+				digest[:],
+				signature,
+			)
+			if err != nil {
+				t.Errorf("invalid signature: %v", err)
+			}
+
+			claimBytes, _ := base64.RawURLEncoding.DecodeString(parts[1])
+			var claims jwtClaims
+			json.Unmarshal(claimBytes, &claims)
+			if claims.Issuer != "clientId" ||
+				claims.Subject != "user@example.com" ||
+				claims.Audience != SandboxAudience {
+				t.Errorf("unexpected claims: %+v", claims)
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{
+				"access_token": "accessToken",
+				"instance_url": "https://example.my.salesforce.com",
+				"id": "https://login.salesforce.com/id/00D/005",
+				"issued_at": "1700000000000"
+			}`))
+		},
+	))
+	defer server.Close()
+
+	authFlow := JWTBearerFlow{
+		ClientId:      "clientId",
+		Username:      "user@example.com",
+		Audience:      SandboxAudience,
+		PrivateKey:    pemBytes,
+		TokenEndpoint: server.URL,
+	}
+
+	token, err := authFlow.NewToken(server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "accessToken" {
+		t.Fatalf(
+			"expected %v, actual %v",
+			"accessToken",
+			token.AccessToken,
+		)
+	}
+	if token.Expiration.UnixMilli() != 1700000000000+3600000 {
+		t.Fatalf(
+			"unexpected expiration %v",
+			token.Expiration,
+		)
+	}
+}
+
+func TestParseRSAPrivateKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: pkcs8,
+	})
+
+	parsed, err := ParseRSAPrivateKey(pemBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.Equal(key) {
+		t.Fatal("expected parsed key to equal generated key")
+	}
+
+	_, err = ParseRSAPrivateKey([]byte("not a key"))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestJWTBearerSignerKeyType(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authFlow := JWTBearerFlow{
+		ClientId: "clientId",
+		Username: "user@example.com",
+		Signer:   key,
+	}
+	_, err = authFlow.NewToken(http.DefaultClient)
+	if !errors.Is(err, ErrPrivateKey) {
+		t.Errorf("expected %v, actual %v", ErrPrivateKey, err)
+	}
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"log/slog"
+)
+
+// the flows and Token implement slog.LogValuer so that logging them never
+// writes credentials. secrets are replaced with Redacted when set.
+
+const Redacted string = "REDACTED"
+
+func redact(
+	key string,
+	secret string,
+) slog.Attr {
+	if len(secret) == 0 {
+		return slog.String(key, "")
+	}
+	return slog.String(key, Redacted)
+}
+
+func (token Token) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("Id", token.Id),
+		redact("AccessToken", token.AccessToken),
+		slog.String("InstanceUrl", token.InstanceUrl),
+		slog.Time("Expiration", token.Expiration),
+		redact("RefreshToken", token.RefreshToken),
+	)
+}
+
+func (flow UsernamePasswordFlow) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("ClientId", flow.ClientId),
+		redact("ClientSecret", flow.ClientSecret),
+		slog.String("Username", flow.Username),
+		redact("Password", flow.Password),
+		redact("SecurityToken", flow.SecurityToken),
+		slog.String("TokenEndpoint", flow.TokenEndpoint),
+	)
+}
+
+func (flow ClientCredentialsFlow) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("ClientId", flow.ClientId),
+		redact("ClientSecret", flow.ClientSecret),
+		slog.String("TokenEndpoint", flow.TokenEndpoint),
+	)
+}
+
+func (flow JWTBearerFlow) LogValue() slog.Value {
+	privateKey := ""
+	if len(flow.PrivateKey) > 0 || flow.Signer != nil {
+		privateKey = Redacted
+	}
+	return slog.GroupValue(
+		slog.String("ClientId", flow.ClientId),
+		slog.String("Username", flow.Username),
+		slog.String("Audience", flow.Audience),
+		slog.String("PrivateKey", privateKey),
+		slog.String("TokenEndpoint", flow.TokenEndpoint),
+	)
+}
+
+func (flow *WebServerFlow) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("ClientId", flow.ClientId),
+		redact("ClientSecret", flow.ClientSecret),
+		slog.String("RedirectUri", flow.RedirectUri),
+		slog.Any("Scopes", flow.Scopes),
+		slog.String("TokenEndpoint", flow.TokenEndpoint),
+		redact("CodeVerifier", flow.CodeVerifier),
+		redact("Code", flow.Code),
+	)
+}
+
+func (flow *RefreshTokenFlow) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("ClientId", flow.ClientId),
+		redact("ClientSecret", flow.ClientSecret),
+		redact("InitialRefreshToken", flow.InitialRefreshToken),
+		slog.String("TokenEndpoint", flow.TokenEndpoint),
+	)
+}
+
+func (flow *DeviceFlow) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("ClientId", flow.ClientId),
+		redact("ClientSecret", flow.ClientSecret),
+		slog.Any("Scopes", flow.Scopes),
+		slog.String("TokenEndpoint", flow.TokenEndpoint),
+	)
+}
+
+func (flow *SessionFlow) LogValue() slog.Value {
+	return slog.GroupValue(
+		redact("AccessToken", flow.AccessToken),
+		slog.String("InstanceUrl", flow.InstanceUrl),
+		slog.String("Id", flow.Id),
+	)
+}
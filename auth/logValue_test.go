@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogValueRedactsSecrets(t *testing.T) {
+	flows := []any{
+		Token{AccessToken: "secret"},
+		UsernamePasswordFlow{ClientSecret: "secret", Password: "secret", SecurityToken: "secret"},
+		ClientCredentialsFlow{ClientSecret: "secret"},
+		JWTBearerFlow{PrivateKey: []byte("secret")},
+		&WebServerFlow{ClientSecret: "secret", CodeVerifier: "secret", Code: "secret"},
+		&RefreshTokenFlow{ClientSecret: "secret", InitialRefreshToken: "secret"},
+		&DeviceFlow{ClientSecret: "secret"},
+		&SessionFlow{AccessToken: "secret"},
+	}
+
+	for _, flow := range flows {
+		var buffer bytes.Buffer
+		slog.New(slog.NewTextHandler(&buffer, nil)).Info("test", "flow", flow)
+
+		output := buffer.String()
+		if strings.Contains(output, "secret") {
+			t.Errorf("expected secrets redacted, actual %v", output)
+		}
+		if !strings.Contains(output, Redacted) {
+			t.Errorf("expected %v, actual %v", Redacted, output)
+		}
+	}
+}
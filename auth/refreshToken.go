@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"net/http"
+	"sync"
+)
+
+// RefreshTokenFlow turns a pre-issued refresh token (from the sf cli, a prior
+// consent, etc.) into access tokens. when salesforce rotates the refresh token
+// the new one replaces the old and OnRotate is called so it can be persisted.
+// the flow holds the current refresh token and must be used as a pointer.
+type RefreshTokenFlow struct {
+	ClientId     string
+	ClientSecret string
+	// refresh token to start from. it is not updated on rotation, use
+	// GetRefreshToken for the current value.
+	InitialRefreshToken string
+	TokenEndpoint       string
+	OnRotate            func(refreshToken string)
+	// revoke only the access token on Revoke. set it when the refresh token
+	// is shared, such as with the sf cli, so the other holders stay logged
+	// in.
+	KeepRefreshToken bool
+
+	mu           sync.Mutex
+	refreshToken string
+	// set once the refresh token is revoked so InitialRefreshToken is not
+	// used again
+	revoked bool
+}
+
+func (flow *RefreshTokenFlow) NewToken(
+	httpClient *http.Client,
+) (Token, error) {
+	return flow.RefreshToken(httpClient)
+}
+func (flow *RefreshTokenFlow) RefreshToken(
+	httpClient *http.Client,
+) (Token, error) {
+	flow.mu.Lock()
+	defer flow.mu.Unlock()
+
+	if len(flow.refreshToken) == 0 && !flow.revoked {
+		flow.refreshToken = flow.InitialRefreshToken
+	}
+
+	tokenResponse, err := refreshTokenGrant(
+		httpClient,
+		flow.TokenEndpoint,
+		flow.ClientId,
+		flow.ClientSecret,
+		flow.refreshToken,
+	)
+	if err != nil {
+		return Token{}, err
+	}
+
+	rotated := len(tokenResponse.RefreshToken) > 0 &&
+		tokenResponse.RefreshToken != flow.refreshToken
+	if rotated {
+		flow.refreshToken = tokenResponse.RefreshToken
+		if flow.OnRotate != nil {
+			flow.OnRotate(flow.refreshToken)
+		}
+	}
+
+	token := tokenResponse.asToken()
+	token.RefreshToken = flow.refreshToken
+	return token, nil
+}
+
+// replaces the current refresh token, such as with one loaded from a token
+// store.
+func (flow *RefreshTokenFlow) SetRefreshToken(
+	refreshToken string,
+) {
+	flow.mu.Lock()
+	defer flow.mu.Unlock()
+
+	flow.refreshToken = refreshToken
+	flow.revoked = false
+}
+
+// returns the current refresh token, including any rotation.
+func (flow *RefreshTokenFlow) GetRefreshToken() string {
+	flow.mu.Lock()
+	defer flow.mu.Unlock()
+
+	if len(flow.refreshToken) == 0 && !flow.revoked {
+		return flow.InitialRefreshToken
+	}
+	return flow.refreshToken
+}
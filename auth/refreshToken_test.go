@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRefreshTokenFlow(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			calls++
+			expected := fmt.Sprintf("refreshToken%d", calls)
+			if r.Form.Get("refresh_token") != expected {
+				t.Errorf(
+					"expected %v, actual %v",
+					expected,
+					r.Form.Get("refresh_token"),
+				)
+			}
+			fmt.Fprintf(
+				w,
+				`{"access_token":"accessToken%d","refresh_token":"refreshToken%d","issued_at":"1700000000000"}`,
+				calls,
+				calls+1,
+			)
+		},
+	))
+	defer server.Close()
+
+	var rotated []string
+	authFlow := RefreshTokenFlow{
+		ClientId:            "clientId",
+		InitialRefreshToken: "refreshToken1",
+		TokenEndpoint:       server.URL,
+		OnRotate: func(refreshToken string) {
+			rotated = append(rotated, refreshToken)
+		},
+	}
+
+	token, err := authFlow.NewToken(server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "accessToken1" {
+		t.Fatalf("unexpected access token %v", token.AccessToken)
+	}
+	token, err = authFlow.RefreshToken(server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "accessToken2" {
+		t.Fatalf("unexpected access token %v", token.AccessToken)
+	}
+
+	if authFlow.GetRefreshToken() != "refreshToken3" {
+		t.Fatalf("unexpected refresh token %v", authFlow.GetRefreshToken())
+	}
+	if len(rotated) != 2 {
+		t.Fatalf("expected 2 rotations, received %d", len(rotated))
+	}
+}
+
+func TestRefreshTokenFlowMissingToken(t *testing.T) {
+	authFlow := RefreshTokenFlow{}
+
+	_, err := authFlow.NewToken(http.DefaultClient)
+	if !errors.Is(err, ErrNoRefreshToken) {
+		t.Fatalf("expected ErrNoRefreshToken, received %v", err)
+	}
+}
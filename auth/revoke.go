@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Revoker is implemented by auth flows that can end the session behind a
+// token. flows holding a refresh token revoke it as well, which also
+// invalidates every access token issued from it, unless KeepRefreshToken is
+// set on the flow.
+type Revoker interface {
+	Revoke(
+		httpClient *http.Client,
+		token Token,
+	) error
+}
+
+// revokes an access or refresh token through the revoke endpoint of the
+// instance the token was issued for.
+func Revoke(
+	httpClient *http.Client,
+	instanceUrl string,
+	token string,
+) error {
+	payload := make(url.Values)
+	payload["token"] = []string{token}
+
+	res, err := httpClient.PostForm(
+		strings.TrimSuffix(instanceUrl, "/")+"/services/oauth2/revoke",
+		payload,
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 200 {
+		return nil
+	}
+	var errorResponse AuthError
+	decodeError := json.NewDecoder(res.Body).Decode(&errorResponse)
+	if decodeError != nil {
+		return errors.Join(
+			AuthError{
+				ErrorCode:        "DECODING_ERROR",
+				ErrorDescription: "error decoding sfdc auth response",
+			},
+			decodeError,
+		)
+	}
+	return errorResponse
+}
+
+// revokes the refresh token when one is given, otherwise the access token.
+func revokeSession(
+	httpClient *http.Client,
+	token Token,
+	refreshToken string,
+) error {
+	if len(refreshToken) > 0 {
+		return Revoke(
+			httpClient,
+			token.InstanceUrl,
+			refreshToken,
+		)
+	}
+	if len(token.AccessToken) == 0 {
+		return nil
+	}
+	return Revoke(
+		httpClient,
+		token.InstanceUrl,
+		token.AccessToken,
+	)
+}
+
+func (flow ClientCredentialsFlow) Revoke(
+	httpClient *http.Client,
+	token Token,
+) error {
+	return revokeSession(httpClient, token, "")
+}
+func (flow UsernamePasswordFlow) Revoke(
+	httpClient *http.Client,
+	token Token,
+) error {
+	return revokeSession(httpClient, token, "")
+}
+func (flow JWTBearerFlow) Revoke(
+	httpClient *http.Client,
+	token Token,
+) error {
+	return revokeSession(httpClient, token, "")
+}
+
+// revokes the refresh token, after which the flow needs a new authorization
+// code to log in again. only the access token when KeepRefreshToken is set.
+func (flow *WebServerFlow) Revoke(
+	httpClient *http.Client,
+	token Token,
+) error {
+	flow.mu.Lock()
+	defer flow.mu.Unlock()
+
+	if flow.KeepRefreshToken {
+		return revokeSession(httpClient, token, "")
+	}
+	err := revokeSession(httpClient, token, flow.refreshToken)
+	if err != nil {
+		return err
+	}
+	flow.refreshToken = ""
+	return nil
+}
+
+// revokes the refresh token, after which the flow can no longer log in. only
+// the access token when KeepRefreshToken is set.
+func (flow *RefreshTokenFlow) Revoke(
+	httpClient *http.Client,
+	token Token,
+) error {
+	flow.mu.Lock()
+	defer flow.mu.Unlock()
+
+	if flow.KeepRefreshToken {
+		return revokeSession(httpClient, token, "")
+	}
+	refreshToken := flow.refreshToken
+	if len(refreshToken) == 0 && !flow.revoked {
+		refreshToken = flow.InitialRefreshToken
+	}
+	err := revokeSession(httpClient, token, refreshToken)
+	if err != nil {
+		return err
+	}
+	flow.refreshToken = ""
+	flow.revoked = true
+	return nil
+}
+
+// revokes the refresh token, the next NewToken starts a new device flow. only
+// the access token when KeepRefreshToken is set.
+func (flow *DeviceFlow) Revoke(
+	httpClient *http.Client,
+	token Token,
+) error {
+	flow.mu.Lock()
+	defer flow.mu.Unlock()
+
+	if flow.KeepRefreshToken {
+		return revokeSession(httpClient, token, "")
+	}
+	err := revokeSession(httpClient, token, flow.refreshToken)
+	if err != nil {
+		return err
+	}
+	flow.refreshToken = ""
+	return nil
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRevoke(t *testing.T) {
+	var revoked []string
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/services/oauth2/revoke" {
+				t.Errorf("unexpected path %s", r.URL.Path)
+			}
+			r.ParseForm()
+			if r.Form.Get("token") == "invalid" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"unsupported_token_type","error_description":"this token type is not supported"}`))
+				return
+			}
+			revoked = append(revoked, r.Form.Get("token"))
+		},
+	))
+	defer server.Close()
+
+	token := Token{
+		AccessToken: "accessToken",
+		InstanceUrl: server.URL,
+	}
+
+	err := ClientCredentialsFlow{}.Revoke(server.Client(), token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// only the access token when the refresh token is kept
+	authFlow := &RefreshTokenFlow{
+		InitialRefreshToken: "refreshToken",
+		KeepRefreshToken:    true,
+	}
+	err = authFlow.Revoke(server.Client(), token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authFlow.KeepRefreshToken = false
+	err = authFlow.Revoke(server.Client(), token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a borrowed session is never revoked unless allowed
+	sessionFlow := &SessionFlow{}
+	err = sessionFlow.Revoke(server.Client(), token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"accessToken", "accessToken", "refreshToken"}
+	if len(revoked) != len(expected) {
+		t.Fatalf("expected %v, actual %v", expected, revoked)
+	}
+	for i := range expected {
+		if revoked[i] != expected[i] {
+			t.Fatalf("expected %v, actual %v", expected, revoked)
+		}
+	}
+
+	// the revoked refresh token is not sent again
+	if authFlow.GetRefreshToken() != "" {
+		t.Errorf("expected %v, actual %v", "", authFlow.GetRefreshToken())
+	}
+	_, err = authFlow.RefreshToken(server.Client())
+	if !errors.Is(err, ErrNoRefreshToken) {
+		t.Errorf("expected %v, actual %v", ErrNoRefreshToken, err)
+	}
+
+	err = Revoke(server.Client(), server.URL, "invalid")
+	authError, ok := err.(AuthError)
+	if !ok || authError.ErrorCode != "unsupported_token_type" {
+		t.Fatalf("expected unsupported_token_type, received %v", err)
+	}
+}
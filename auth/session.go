@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SessionFlow wraps an access token obtained elsewhere, e.g. from an apex
+// callout, a canvas signed request or the sf cli. when the api rejects the
+// token OnRefresh is called to obtain a new one; without it RefreshToken fails
+// with ErrSessionExpired. a zero Expiration is treated as unknown, the token is
+// then only replaced once the api rejects it. the flow keeps the latest token
+// and must be used as a pointer.
+type SessionFlow struct {
+	AccessToken string
+	InstanceUrl string
+	Id          string
+	Expiration  time.Time
+	OnRefresh   func(httpClient *http.Client) (Token, error)
+	// allow Revoke to end the session. off by default as revoking would log
+	// out the program the session was borrowed from.
+	RevokeSession bool
+
+	mu      sync.Mutex
+	current Token
+}
+
+// expiration used for tokens whose lifetime is unknown.
+var noExpiration = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+func (flow *SessionFlow) NewToken(
+	_ *http.Client,
+) (Token, error) {
+	flow.mu.Lock()
+	defer flow.mu.Unlock()
+
+	if len(flow.current.AccessToken) > 0 {
+		return flow.current, nil
+	}
+	if len(flow.AccessToken) == 0 || len(flow.InstanceUrl) == 0 {
+		return Token{}, ErrSession
+	}
+
+	expiration := flow.Expiration
+	if expiration.IsZero() {
+		expiration = noExpiration
+	}
+	flow.current = Token{
+		Id:          flow.Id,
+		AccessToken: flow.AccessToken,
+		InstanceUrl: flow.InstanceUrl,
+		Expiration:  expiration,
+	}
+	return flow.current, nil
+}
+func (flow *SessionFlow) RefreshToken(
+	httpClient *http.Client,
+) (Token, error) {
+	flow.mu.Lock()
+	defer flow.mu.Unlock()
+
+	if flow.OnRefresh == nil {
+		return Token{}, ErrSessionExpired
+	}
+	token, err := flow.OnRefresh(httpClient)
+	if err != nil {
+		return Token{}, err
+	}
+	if token.Expiration.IsZero() {
+		token.Expiration = noExpiration
+	}
+	flow.current = token
+
+	return token, nil
+}
+
+// revokes the access token when RevokeSession is set, otherwise does
+// nothing as the session belongs to whoever issued it.
+func (flow *SessionFlow) Revoke(
+	httpClient *http.Client,
+	token Token,
+) error {
+	if !flow.RevokeSession {
+		return nil
+	}
+	return revokeSession(httpClient, token, "")
+}
+
+var ErrSession = errors.New("session flow requires an access token and instance url")
+var ErrSessionExpired = errors.New("session expired and no refresh callback is set")
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestSessionFlow(t *testing.T) {
+	authFlow := SessionFlow{
+		AccessToken: "accessToken",
+		InstanceUrl: "https://example.my.salesforce.com",
+	}
+
+	token, err := authFlow.NewToken(http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "accessToken" || token.Expiration.IsZero() {
+		t.Fatalf("unexpected token %v", token)
+	}
+
+	_, err = authFlow.RefreshToken(http.DefaultClient)
+	if !errors.Is(err, ErrSessionExpired) {
+		t.Fatalf("expected ErrSessionExpired, received %v", err)
+	}
+
+	authFlow.OnRefresh = func(_ *http.Client) (Token, error) {
+		return Token{
+			AccessToken: "accessToken2",
+			InstanceUrl: "https://example.my.salesforce.com",
+		}, nil
+	}
+	token, err = authFlow.RefreshToken(http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "accessToken2" {
+		t.Fatalf("unexpected token %v", token)
+	}
+
+	token, err = authFlow.NewToken(http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "accessToken2" {
+		t.Fatalf("expected refreshed token to be kept, received %v", token)
+	}
+}
+
+func TestSessionFlowMissingToken(t *testing.T) {
+	authFlow := SessionFlow{}
+
+	_, err := authFlow.NewToken(http.DefaultClient)
+	if !errors.Is(err, ErrSession) {
+		t.Fatalf("expected ErrSession, received %v", err)
+	}
+}
@@ -0,0 +1,323 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// builds a RefreshTokenFlow from an sfdx auth url of the form
+// force://clientId:clientSecret:refreshToken@instance, as printed by
+// `sf org display --verbose`. the client secret may be empty. the refresh
+// token is shared with the cli so the flow has KeepRefreshToken set, clear it
+// to log the cli out as well on Revoke.
+func ParseSfdxAuthUrl(
+	authUrl string,
+) (*RefreshTokenFlow, error) {
+	rest, ok := strings.CutPrefix(
+		strings.TrimSpace(authUrl),
+		"force://",
+	)
+	if !ok {
+		return nil, ErrSfdxAuthUrl
+	}
+	at := strings.LastIndex(rest, "@")
+	if at < 0 {
+		return nil, ErrSfdxAuthUrl
+	}
+	credentials := strings.SplitN(rest[:at], ":", 3)
+	if len(credentials) != 3 ||
+		len(credentials[0]) == 0 ||
+		len(credentials[2]) == 0 {
+		return nil, ErrSfdxAuthUrl
+	}
+	instanceUrl := rest[at+1:]
+	if len(instanceUrl) == 0 {
+		return nil, ErrSfdxAuthUrl
+	}
+
+	return &RefreshTokenFlow{
+		ClientId:            credentials[0],
+		ClientSecret:        credentials[1],
+		InitialRefreshToken: credentials[2],
+		TokenEndpoint:       sfdxTokenEndpoint(instanceUrl),
+		KeepRefreshToken:    true,
+	}, nil
+}
+
+// options for SfdxAuthFlow. the cli encrypts the tokens in its auth files,
+// they are decrypted without configuration when the key is in the cli's
+// generic keychain file, <Dir>/key.json, which is what the cli uses on linux
+// without libsecret. keys in the macos and windows keychains or libsecret are
+// not read, for those either
+//   - set UseCli to ask the sf cli for the org through `sf org display`, or
+//   - set Decrypt.
+//
+// otherwise SfdxAuthFlow fails with ErrSfdxEncrypted.
+type SfdxOptions struct {
+	// directory holding the cli state, defaults to ~/.sfdx.
+	Dir string
+	// decrypts token values from the auth file, used instead of the key
+	// file when set.
+	Decrypt func(value string) (string, error)
+	// run the cli when the tokens cannot be decrypted. off by default so
+	// no external program is started unless asked for.
+	UseCli bool
+	// cli run when UseCli is set, defaults to sf on the PATH.
+	Cli string
+
+	// overridden in tests
+	runCli func(name string, args ...string) ([]byte, error)
+}
+
+// the parts of a cli auth file (~/.sfdx/<username>.json) used to log in.
+type sfdxAuthFile struct {
+	Username     string `json:"username"`
+	OrgId        string `json:"orgId"`
+	InstanceUrl  string `json:"instanceUrl"`
+	LoginUrl     string `json:"loginUrl"`
+	ClientId     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+type sfdxAliasFile struct {
+	Orgs map[string]string `json:"orgs"`
+}
+
+// builds an AuthFlow from an org the sf cli is already authenticated to.
+// aliasOrUsername is resolved through the cli alias file. orgs with a refresh
+// token produce a *RefreshTokenFlow, orgs with only an access token produce
+// a *SessionFlow. see SfdxOptions for how encrypted tokens are read.
+func SfdxAuthFlow(
+	aliasOrUsername string,
+	options *SfdxOptions,
+) (AuthFlow, error) {
+	var opts SfdxOptions
+	if options != nil {
+		opts = *options
+	}
+	dir := opts.Dir
+	if len(dir) == 0 {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(home, ".sfdx")
+	}
+
+	username := aliasOrUsername
+	aliasData, err := os.ReadFile(filepath.Join(dir, "alias.json"))
+	if err == nil {
+		var aliases sfdxAliasFile
+		err = json.Unmarshal(aliasData, &aliases)
+		if err != nil {
+			return nil, errors.Join(
+				ErrSfdxAuthFile,
+				err,
+			)
+		}
+		if resolved, ok := aliases.Orgs[aliasOrUsername]; ok {
+			username = resolved
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	// the username names a file in dir, it must not lead out of it
+	if strings.ContainsAny(username, `/\`) ||
+		!filepath.IsLocal(username+".json") {
+		return nil, errors.Join(
+			ErrSfdxAuthFile,
+			fmt.Errorf("invalid username %q", username),
+		)
+	}
+
+	authData, err := os.ReadFile(filepath.Join(dir, username+".json"))
+	if err != nil {
+		return nil, errors.Join(
+			ErrSfdxAuthFile,
+			err,
+		)
+	}
+	var authFile sfdxAuthFile
+	err = json.Unmarshal(authData, &authFile)
+	if err != nil {
+		return nil, errors.Join(
+			ErrSfdxAuthFile,
+			err,
+		)
+	}
+
+	decrypt, err := opts.decrypter(dir)
+	if err != nil {
+		return nil, err
+	}
+	authFlow, err := sfdxFileFlow(authFile, decrypt)
+	if !errors.Is(err, ErrSfdxEncrypted) || !opts.UseCli {
+		return authFlow, err
+	}
+
+	// the key is not available, the cli can still decrypt the tokens
+	authFlow, cliErr := opts.cliFlow(username)
+	if cliErr != nil {
+		return nil, errors.Join(
+			err,
+			cliErr,
+		)
+	}
+	return authFlow, nil
+}
+
+// builds the flow from an auth file, failing with ErrSfdxEncrypted when a
+// token is encrypted and decrypt is nil.
+func sfdxFileFlow(
+	authFile sfdxAuthFile,
+	decrypt func(value string) (string, error),
+) (AuthFlow, error) {
+	if len(authFile.RefreshToken) > 0 {
+		refreshToken, err := sfdxDecrypt(decrypt, authFile.RefreshToken)
+		if err != nil {
+			return nil, err
+		}
+		clientSecret, err := sfdxDecrypt(decrypt, authFile.ClientSecret)
+		if err != nil {
+			return nil, err
+		}
+		loginUrl := authFile.LoginUrl
+		if len(loginUrl) == 0 {
+			loginUrl = authFile.InstanceUrl
+		}
+		return &RefreshTokenFlow{
+			ClientId:            authFile.ClientId,
+			ClientSecret:        clientSecret,
+			InitialRefreshToken: refreshToken,
+			TokenEndpoint:       sfdxTokenEndpoint(loginUrl),
+			KeepRefreshToken:    true,
+		}, nil
+	}
+
+	accessToken, err := sfdxDecrypt(decrypt, authFile.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	return &SessionFlow{
+		AccessToken: accessToken,
+		InstanceUrl: authFile.InstanceUrl,
+	}, nil
+}
+
+// returns plain text values untouched. encrypted cli values have the form
+// <hex iv and ciphertext>:<hex auth tag>.
+func sfdxDecrypt(
+	decrypt func(value string) (string, error),
+	value string,
+) (string, error) {
+	if !isSfdxEncrypted(value) {
+		return value, nil
+	}
+	if decrypt == nil {
+		return "", ErrSfdxEncrypted
+	}
+	return decrypt(value)
+}
+
+func isSfdxEncrypted(
+	value string,
+) bool {
+	data, tag, ok := strings.Cut(value, ":")
+	if !ok || len(data) == 0 || len(tag) != 32 {
+		return false
+	}
+	for _, c := range data + tag {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// the parts of `sf org display --verbose --json` used to log in.
+type sfdxDisplayOutput struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+	Result  struct {
+		AccessToken string `json:"accessToken"`
+		InstanceUrl string `json:"instanceUrl"`
+		SfdxAuthUrl string `json:"sfdxAuthUrl"`
+	} `json:"result"`
+}
+
+// asks the cli for the org, which decrypts the tokens with the key from the
+// os keychain.
+func (opts SfdxOptions) cliFlow(
+	username string,
+) (AuthFlow, error) {
+	cli := opts.Cli
+	if len(cli) == 0 {
+		cli = "sf"
+	}
+	run := opts.runCli
+	if run == nil {
+		run = func(name string, args ...string) ([]byte, error) {
+			return exec.Command(name, args...).Output()
+		}
+	}
+
+	output, err := run(
+		cli,
+		"org", "display",
+		"--target-org="+username,
+		"--verbose",
+		"--json",
+	)
+	var display sfdxDisplayOutput
+	decodeError := json.Unmarshal(output, &display)
+	if decodeError != nil {
+		return nil, errors.Join(
+			ErrSfdxCli,
+			err,
+			decodeError,
+		)
+	}
+	if display.Status != 0 {
+		return nil, errors.Join(
+			ErrSfdxCli,
+			errors.New(display.Message),
+		)
+	}
+
+	if len(display.Result.SfdxAuthUrl) > 0 {
+		return ParseSfdxAuthUrl(display.Result.SfdxAuthUrl)
+	}
+	if len(display.Result.AccessToken) == 0 {
+		return nil, errors.Join(
+			ErrSfdxCli,
+			errors.New("no access token"),
+		)
+	}
+	return &SessionFlow{
+		AccessToken: display.Result.AccessToken,
+		InstanceUrl: display.Result.InstanceUrl,
+	}, nil
+}
+
+func sfdxTokenEndpoint(
+	instanceUrl string,
+) string {
+	if !strings.Contains(instanceUrl, "://") {
+		instanceUrl = "https://" + instanceUrl
+	}
+	return strings.TrimSuffix(instanceUrl, "/") + "/services/oauth2/token"
+}
+
+var ErrSfdxAuthUrl = errors.New("invalid sfdx auth url")
+var ErrSfdxAuthFile = errors.New("error reading sfdx auth file")
+var ErrSfdxEncrypted = errors.New(
+	"sfdx auth file is encrypted and the key is not available, set SfdxOptions.UseCli or SfdxOptions.Decrypt, or use an sfdx auth url",
+)
+var ErrSfdxCli = errors.New("error reading org from the sf cli")
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// the cli's generic keychain, <dir>/key.json.
+type sfdxKeyFile struct {
+	Service string `json:"service"`
+	Account string `json:"account"`
+	Key     string `json:"key"`
+}
+
+// Decrypt when set, otherwise decryption with the key from the generic
+// keychain file. nil when neither is available.
+func (opts SfdxOptions) decrypter(
+	dir string,
+) (func(value string) (string, error), error) {
+	if opts.Decrypt != nil {
+		return opts.Decrypt, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "key.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Join(
+			ErrSfdxAuthFile,
+			err,
+		)
+	}
+	var keyFile sfdxKeyFile
+	err = json.Unmarshal(data, &keyFile)
+	if err != nil || len(keyFile.Key) == 0 {
+		return nil, errors.Join(
+			ErrSfdxAuthFile,
+			errors.New("invalid key.json"),
+			err,
+		)
+	}
+
+	return func(value string) (string, error) {
+		return sfdxDecryptWithKey(keyFile.Key, value)
+	}, nil
+}
+
+// decrypts a value the cli encrypted with aes-256-gcm. the cli has two
+// formats told apart by the key length: the original uses the 32 character
+// key and the 12 character hex iv as raw bytes, the newer one uses a 64
+// character hex key and a 24 character hex iv.
+func sfdxDecryptWithKey(
+	key string,
+	value string,
+) (string, error) {
+	data, tag, _ := strings.Cut(value, ":")
+
+	var keyBytes, iv []byte
+	var ciphertext string
+	var err error
+	switch len(key) {
+	case 32:
+		if len(data) < 12 {
+			return "", ErrSfdxDecrypt
+		}
+		keyBytes = []byte(key)
+		iv = []byte(data[:12])
+		ciphertext = data[12:]
+	case 64:
+		if len(data) < 24 {
+			return "", ErrSfdxDecrypt
+		}
+		keyBytes, err = hex.DecodeString(key)
+		if err != nil {
+			return "", errors.Join(ErrSfdxDecrypt, err)
+		}
+		iv, err = hex.DecodeString(data[:24])
+		if err != nil {
+			return "", errors.Join(ErrSfdxDecrypt, err)
+		}
+		ciphertext = data[24:]
+	default:
+		return "", ErrSfdxDecrypt
+	}
+
+	sealed, err := hex.DecodeString(ciphertext + tag)
+	if err != nil {
+		return "", errors.Join(ErrSfdxDecrypt, err)
+	}
+	block, err := aes.NewCipher(keyBytes)
+	if err != nil {
+		return "", errors.Join(ErrSfdxDecrypt, err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", errors.Join(ErrSfdxDecrypt, err)
+	}
+	plaintext, err := gcm.Open(nil, iv, sealed, nil)
+	if err != nil {
+		return "", errors.Join(ErrSfdxDecrypt, err)
+	}
+	return string(plaintext), nil
+}
+
+var ErrSfdxDecrypt = errors.New("error decrypting sfdx auth file")
@@ -0,0 +1,281 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSfdxAuthUrl(t *testing.T) {
+	authFlow, err := ParseSfdxAuthUrl(
+		"force://PlatformCLI::5Aep861refreshToken@example.my.salesforce.com",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authFlow.ClientId != "PlatformCLI" ||
+		authFlow.ClientSecret != "" ||
+		authFlow.InitialRefreshToken != "5Aep861refreshToken" {
+		t.Fatalf("unexpected flow %+v", authFlow)
+	}
+
+	expected := "https://example.my.salesforce.com/services/oauth2/token"
+	actual := authFlow.TokenEndpoint
+	if expected != actual {
+		t.Fatalf(
+			"expected %v, actual %v",
+			expected,
+			actual,
+		)
+	}
+
+	_, err = ParseSfdxAuthUrl("https://example.my.salesforce.com")
+	if !errors.Is(err, ErrSfdxAuthUrl) {
+		t.Fatalf("expected ErrSfdxAuthUrl, received %v", err)
+	}
+}
+
+func TestSfdxAuthFlow(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(
+		filepath.Join(dir, "alias.json"),
+		[]byte(`{"orgs":{"dev":"user@example.com"}}`),
+		0600,
+	)
+	os.WriteFile(
+		filepath.Join(dir, "user@example.com.json"),
+		[]byte(`{
+			"username": "user@example.com",
+			"instanceUrl": "https://example.my.salesforce.com",
+			"loginUrl": "https://login.salesforce.com",
+			"clientId": "PlatformCLI",
+			"refreshToken": "5Aep861refreshToken"
+		}`),
+		0600,
+	)
+	os.WriteFile(
+		filepath.Join(dir, "encrypted@example.com.json"),
+		[]byte(`{
+			"instanceUrl": "https://example.my.salesforce.com",
+			"refreshToken": "0a1b2c3d4e5f60718293a4b5c6d7e8f9:0123456789abcdef0123456789abcdef"
+		}`),
+		0600,
+	)
+
+	authFlow, err := SfdxAuthFlow(
+		"dev",
+		&SfdxOptions{
+			Dir: dir,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshFlow, ok := authFlow.(*RefreshTokenFlow)
+	if !ok {
+		t.Fatalf("expected *RefreshTokenFlow, received %T", authFlow)
+	}
+	if refreshFlow.InitialRefreshToken != "5Aep861refreshToken" ||
+		refreshFlow.TokenEndpoint != ProductionTokenEndpoint {
+		t.Fatalf("unexpected flow %+v", refreshFlow)
+	}
+
+	_, err = SfdxAuthFlow(
+		"encrypted@example.com",
+		&SfdxOptions{
+			Dir:    dir,
+			UseCli: true,
+			runCli: func(string, ...string) ([]byte, error) {
+				return nil, exec.ErrNotFound
+			},
+		},
+	)
+	if !errors.Is(err, ErrSfdxEncrypted) {
+		t.Fatalf("expected ErrSfdxEncrypted, received %v", err)
+	}
+
+	// the cli is only run when asked for
+	_, err = SfdxAuthFlow(
+		"encrypted@example.com",
+		&SfdxOptions{
+			Dir: dir,
+			runCli: func(string, ...string) ([]byte, error) {
+				t.Error("expected the cli not to run")
+				return nil, exec.ErrNotFound
+			},
+		},
+	)
+	if !errors.Is(err, ErrSfdxEncrypted) {
+		t.Errorf("expected %v, actual %v", ErrSfdxEncrypted, err)
+	}
+
+	// aliases and usernames cannot name files outside of dir
+	os.WriteFile(
+		filepath.Join(dir, "alias.json"),
+		[]byte(`{"orgs":{"dev":"../user@example.com"}}`),
+		0600,
+	)
+	for _, name := range []string{"dev", "../user@example.com", `..\user`} {
+		_, err = SfdxAuthFlow(
+			name,
+			&SfdxOptions{
+				Dir: dir,
+			},
+		)
+		if !errors.Is(err, ErrSfdxAuthFile) {
+			t.Errorf("%q: expected %v, actual %v", name, ErrSfdxAuthFile, err)
+		}
+	}
+}
+
+// encrypts value the way the cli does for the given key, see
+// sfdxDecryptWithKey.
+func sfdxEncrypt(
+	t *testing.T,
+	key string,
+	value string,
+) string {
+	var keyBytes, iv []byte
+	var ivHex string
+	if len(key) == 32 {
+		keyBytes = []byte(key)
+		ivHex = "0a1b2c3d4e5f"
+		iv = []byte(ivHex)
+	} else {
+		keyBytes, _ = hex.DecodeString(key)
+		ivHex = "0a1b2c3d4e5f0a1b2c3d4e5f"
+		iv, _ = hex.DecodeString(ivHex)
+	}
+	block, err := aes.NewCipher(keyBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed := gcm.Seal(nil, iv, []byte(value), nil)
+	ciphertext := sealed[:len(sealed)-gcm.Overhead()]
+	tag := sealed[len(sealed)-gcm.Overhead():]
+
+	return ivHex + hex.EncodeToString(ciphertext) + ":" + hex.EncodeToString(tag)
+}
+
+func TestSfdxAuthFlowKeyFile(t *testing.T) {
+	keys := []string{
+		"0123456789abcdef0123456789abcdef",
+		"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+	}
+	for _, key := range keys {
+		dir := t.TempDir()
+		os.WriteFile(
+			filepath.Join(dir, "key.json"),
+			[]byte(fmt.Sprintf(`{"service":"sfdx","account":"local","key":%q}`, key)),
+			0600,
+		)
+		os.WriteFile(
+			filepath.Join(dir, "user@example.com.json"),
+			[]byte(fmt.Sprintf(
+				`{"instanceUrl":"https://example.my.salesforce.com","clientId":"PlatformCLI","refreshToken":%q}`,
+				sfdxEncrypt(t, key, "5Aep861refreshToken"),
+			)),
+			0600,
+		)
+
+		authFlow, err := SfdxAuthFlow(
+			"user@example.com",
+			&SfdxOptions{
+				Dir: dir,
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		refreshFlow, ok := authFlow.(*RefreshTokenFlow)
+		if !ok || refreshFlow.InitialRefreshToken != "5Aep861refreshToken" {
+			t.Errorf("expected %v, actual %+v", "5Aep861refreshToken", authFlow)
+		}
+	}
+}
+
+func TestSfdxAuthFlowCli(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(
+		filepath.Join(dir, "user@example.com.json"),
+		[]byte(`{
+			"instanceUrl": "https://example.my.salesforce.com",
+			"refreshToken": "0a1b2c3d4e5f60718293a4b5c6d7e8f9:0123456789abcdef0123456789abcdef"
+		}`),
+		0600,
+	)
+
+	var args []string
+	authFlow, err := SfdxAuthFlow(
+		"user@example.com",
+		&SfdxOptions{
+			Dir:    dir,
+			UseCli: true,
+			runCli: func(name string, arg ...string) ([]byte, error) {
+				args = append([]string{name}, arg...)
+				return []byte(`{"status":0,"result":{
+					"accessToken":"accessToken",
+					"instanceUrl":"https://example.my.salesforce.com",
+					"sfdxAuthUrl":"force://PlatformCLI::5Aep861refreshToken@example.my.salesforce.com"
+				}}`), nil
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshFlow, ok := authFlow.(*RefreshTokenFlow)
+	if !ok || refreshFlow.InitialRefreshToken != "5Aep861refreshToken" {
+		t.Errorf("expected %v, actual %+v", "5Aep861refreshToken", authFlow)
+	}
+
+	expected := "sf org display --target-org=user@example.com --verbose --json"
+	actual := strings.Join(args, " ")
+	if expected != actual {
+		t.Errorf("expected %v, actual %v", expected, actual)
+	}
+}
+
+// testdata/sfdx holds auth files encrypted by node's crypto module with the
+// calls @salesforce/core makes for its original (v1) and hex (v2) keys.
+func TestSfdxAuthFlowFixtures(t *testing.T) {
+	for _, version := range []string{"v1", "v2"} {
+		authFlow, err := SfdxAuthFlow(
+			"user@example.com",
+			&SfdxOptions{
+				Dir: filepath.Join("testdata", "sfdx", version),
+			},
+		)
+		if err != nil {
+			t.Fatalf("%s: %v", version, err)
+		}
+		refreshFlow, ok := authFlow.(*RefreshTokenFlow)
+		if !ok || refreshFlow.InitialRefreshToken != "5Aep861refreshToken" {
+			t.Errorf("%s: expected %v, actual %+v", version, "5Aep861refreshToken", authFlow)
+		}
+
+		decrypt, err := SfdxOptions{}.decrypter(filepath.Join("testdata", "sfdx", version))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := os.ReadFile(filepath.Join("testdata", "sfdx", version, "user@example.com.json"))
+		var authFile sfdxAuthFile
+		json.Unmarshal(data, &authFile)
+		accessToken, err := decrypt(authFile.AccessToken)
+		if err != nil || accessToken != "00D000000000001!accessToken" {
+			t.Errorf("%s: expected %v, actual %v (%v)", version, "00D000000000001!accessToken", accessToken, err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// TokenKey identifies the org and user a stored token belongs to.
+type TokenKey struct {
+	OrgId    string
+	Username string
+}
+
+func (key TokenKey) String() string {
+	return key.OrgId + "/" + key.Username
+}
+
+// TokenStore persists tokens so they can survive restarts and be shared between
+// processes. Load reports false when no token is stored for the key.
+type TokenStore interface {
+	Load(key TokenKey) (Token, bool, error)
+	Save(key TokenKey, token Token) error
+	Invalidate(key TokenKey) error
+}
+
+// RefreshTokenSetter is implemented by flows that refresh with a refresh
+// token. clients pass the refresh token of a token loaded from a TokenStore to
+// the flow so it can be refreshed by a process that did not log in itself.
+type RefreshTokenSetter interface {
+	SetRefreshToken(refreshToken string)
+}
+
+// MemoryTokenStore keeps tokens in memory. it is safe for concurrent use and
+// can be shared by clients within a single process.
+type MemoryTokenStore struct {
+	mu     sync.RWMutex
+	tokens map[TokenKey]Token
+}
+
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{
+		tokens: map[TokenKey]Token{},
+	}
+}
+
+func (store *MemoryTokenStore) Load(
+	key TokenKey,
+) (Token, bool, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	token, ok := store.tokens[key]
+	return token, ok, nil
+}
+func (store *MemoryTokenStore) Save(
+	key TokenKey,
+	token Token,
+) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.tokens[key] = token
+	return nil
+}
+func (store *MemoryTokenStore) Invalidate(
+	key TokenKey,
+) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.tokens, key)
+	return nil
+}
+
+// FileTokenStore keeps one json file per key in Dir. files are written with
+// owner only permissions and replaced atomically, so several processes can
+// share the same directory. files hold the refresh token as well as the access
+// token.
+type FileTokenStore struct {
+	Dir string
+}
+
+func (store FileTokenStore) path(
+	key TokenKey,
+) string {
+	digest := sha256.Sum256([]byte(key.String()))
+	return filepath.Join(
+		store.Dir,
+		hex.EncodeToString(digest[:])+".json",
+	)
+}
+
+func (store FileTokenStore) Load(
+	key TokenKey,
+) (Token, bool, error) {
+	data, err := os.ReadFile(store.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return Token{}, false, nil
+	}
+	if err != nil {
+		return Token{}, false, err
+	}
+
+	var token Token
+	err = json.Unmarshal(data, &token)
+	if err != nil {
+		return Token{}, false, errors.Join(
+			ErrTokenStore,
+			err,
+		)
+	}
+	return token, true, nil
+}
+func (store FileTokenStore) Save(
+	key TokenKey,
+	token Token,
+) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(store.Dir, 0700)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(store.Dir, ".token-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), store.path(key))
+}
+func (store FileTokenStore) Invalidate(
+	key TokenKey,
+) error {
+	err := os.Remove(store.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+var ErrTokenStore = errors.New("error reading stored token")
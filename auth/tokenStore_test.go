@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenStores(t *testing.T) {
+	stores := map[string]TokenStore{
+		"memory": NewMemoryTokenStore(),
+		"file": FileTokenStore{
+			Dir: t.TempDir(),
+		},
+	}
+	key := TokenKey{
+		OrgId:    "00D000000000001",
+		Username: "user@example.com",
+	}
+	token := Token{
+		Id:          "https://login.salesforce.com/id/00D/005",
+		AccessToken: "accessToken",
+		InstanceUrl: "https://example.my.salesforce.com",
+		Expiration:  time.UnixMilli(1700000000000).UTC(),
+	}
+
+	for name, store := range stores {
+		t.Run(
+			name,
+			func(t *testing.T) {
+				_, ok, err := store.Load(key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if ok {
+					t.Fatal("expected empty store")
+				}
+
+				err = store.Save(key, token)
+				if err != nil {
+					t.Fatal(err)
+				}
+				loaded, ok, err := store.Load(key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !ok || loaded.AccessToken != token.AccessToken ||
+					!loaded.Expiration.Equal(token.Expiration) {
+					t.Fatalf(
+						"expected %v, actual %v",
+						token,
+						loaded,
+					)
+				}
+
+				err = store.Invalidate(key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				_, ok, err = store.Load(key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if ok {
+					t.Fatal("expected token to be invalidated")
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// WebServerFlow implements the oauth authorization code flow with pkce. the
+// caller sends the user to AuthorizationUrl, receives the code on its redirect
+// uri and sets Code before handing the flow to a client. the refresh token
+// returned by the exchange is kept by the flow and used by RefreshToken, so
+// the flow must be used as a pointer. AuthorizationEndpoint and TokenEndpoint
+// default to the production endpoints, set both for a sandbox or my domain.
+type WebServerFlow struct {
+	ClientId              string
+	ClientSecret          string
+	RedirectUri           string
+	Scopes                []string
+	AuthorizationEndpoint string
+	TokenEndpoint         string
+	// verifier used to derive the pkce challenge, see NewCodeVerifier.
+	CodeVerifier string
+	// authorization code received on the redirect uri.
+	Code string
+	// revoke only the access token on Revoke, leaving the refresh token
+	// and every session issued from it usable.
+	KeepRefreshToken bool
+
+	mu           sync.Mutex
+	refreshToken string
+}
+
+// generates a random pkce code verifier (43 characters of base64url).
+func NewCodeVerifier() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// derives the S256 pkce code challenge for a verifier.
+func CodeChallenge(
+	codeVerifier string,
+) string {
+	digest := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+// builds the url the user should be sent to in order to grant access. state
+// is echoed back on the redirect uri and should be verified by the caller.
+func (flow *WebServerFlow) AuthorizationUrl(
+	state string,
+) (string, error) {
+	endpoint := flow.AuthorizationEndpoint
+	if len(endpoint) == 0 {
+		endpoint = ProductionAuthorizationEndpoint
+	}
+	ret, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	q := ret.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", flow.ClientId)
+	q.Set("redirect_uri", flow.RedirectUri)
+	if len(flow.Scopes) > 0 {
+		q.Set("scope", strings.Join(flow.Scopes, " "))
+	}
+	if len(state) > 0 {
+		q.Set("state", state)
+	}
+	if len(flow.CodeVerifier) > 0 {
+		q.Set("code_challenge", CodeChallenge(flow.CodeVerifier))
+		q.Set("code_challenge_method", "S256")
+	}
+	ret.RawQuery = q.Encode()
+
+	return ret.String(), nil
+}
+
+// exchanges the authorization code for a token. authorization codes are single
+// use, so once a refresh token is held subsequent calls refresh instead.
+func (flow *WebServerFlow) NewToken(
+	httpClient *http.Client,
+) (Token, error) {
+	flow.mu.Lock()
+	defer flow.mu.Unlock()
+
+	if len(flow.refreshToken) > 0 {
+		return flow.refresh(httpClient)
+	}
+	if len(flow.Code) == 0 {
+		return Token{}, ErrNoAuthorizationCode
+	}
+
+	payload := make(url.Values)
+	payload["grant_type"] = []string{"authorization_code"}
+	payload["code"] = []string{flow.Code}
+	payload["client_id"] = []string{flow.ClientId}
+	if len(flow.ClientSecret) > 0 {
+		payload["client_secret"] = []string{flow.ClientSecret}
+	}
+	payload["redirect_uri"] = []string{flow.RedirectUri}
+	if len(flow.CodeVerifier) > 0 {
+		payload["code_verifier"] = []string{flow.CodeVerifier}
+	}
+
+	tokenResponse, err := requestToken(
+		httpClient,
+		flow.tokenEndpoint(),
+		payload,
+	)
+	if err != nil {
+		return Token{}, err
+	}
+	flow.Code = ""
+	flow.refreshToken = tokenResponse.RefreshToken
+
+	return tokenResponse.asToken(), nil
+}
+func (flow *WebServerFlow) RefreshToken(
+	httpClient *http.Client,
+) (Token, error) {
+	flow.mu.Lock()
+	defer flow.mu.Unlock()
+
+	return flow.refresh(httpClient)
+}
+
+// must be called with mu held.
+func (flow *WebServerFlow) refresh(
+	httpClient *http.Client,
+) (Token, error) {
+	tokenResponse, err := refreshTokenGrant(
+		httpClient,
+		flow.tokenEndpoint(),
+		flow.ClientId,
+		flow.ClientSecret,
+		flow.refreshToken,
+	)
+	if err != nil {
+		return Token{}, err
+	}
+	if len(tokenResponse.RefreshToken) > 0 {
+		flow.refreshToken = tokenResponse.RefreshToken
+	}
+	token := tokenResponse.asToken()
+	token.RefreshToken = flow.refreshToken
+	return token, nil
+}
+
+func (flow *WebServerFlow) tokenEndpoint() string {
+	if len(flow.TokenEndpoint) == 0 {
+		return ProductionTokenEndpoint
+	}
+	return flow.TokenEndpoint
+}
+
+// replaces the refresh token, such as with one loaded from a token store, so
+// the flow refreshes instead of starting a new login.
+func (flow *WebServerFlow) SetRefreshToken(
+	refreshToken string,
+) {
+	flow.mu.Lock()
+	defer flow.mu.Unlock()
+
+	flow.refreshToken = refreshToken
+}
+
+// returns the refresh token obtained from the code exchange so it can be
+// persisted by the caller.
+func (flow *WebServerFlow) GetRefreshToken() string {
+	flow.mu.Lock()
+	defer flow.mu.Unlock()
+
+	return flow.refreshToken
+}
+
+var ErrNoAuthorizationCode = errors.New("no authorization code")
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestWebServerAuthorizationUrl(t *testing.T) {
+	authFlow := WebServerFlow{
+		ClientId:              "clientId",
+		RedirectUri:           "https://example.com/callback",
+		Scopes:                []string{"api", "refresh_token"},
+		AuthorizationEndpoint: SandboxAuthorizationEndpoint,
+		CodeVerifier:          "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk",
+	}
+
+	authorizationUrl, err := authFlow.AuthorizationUrl("state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := url.Parse(authorizationUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := parsed.Query()
+
+	// challenge from the example in rfc 7636 appendix b
+	expected := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	actual := q.Get("code_challenge")
+	if expected != actual {
+		t.Fatalf(
+			"expected %v, actual %v",
+			expected,
+			actual,
+		)
+	}
+	if q.Get("scope") != "api refresh_token" || q.Get("state") != "state" {
+		t.Fatalf("unexpected query: %v", q)
+	}
+}
+
+func TestWebServerFlow(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			switch r.Form.Get("grant_type") {
+			case "authorization_code":
+				if r.Form.Get("code") != "code" ||
+					r.Form.Get("code_verifier") != "verifier" {
+					t.Errorf("unexpected form: %v", r.Form)
+				}
+				w.Write([]byte(`{
+					"access_token": "accessToken1",
+					"refresh_token": "refreshToken",
+					"instance_url": "https://example.my.salesforce.com",
+					"issued_at": "1700000000000"
+				}`))
+			case "refresh_token":
+				if r.Form.Get("refresh_token") != "refreshToken" {
+					t.Errorf("unexpected form: %v", r.Form)
+				}
+				w.Write([]byte(`{
+					"access_token": "accessToken2",
+					"instance_url": "https://example.my.salesforce.com",
+					"issued_at": "1700000000000"
+				}`))
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{
+					"error": "unsupported_grant_type",
+					"error_description": "grant type not supported"
+				}`))
+			}
+		},
+	))
+	defer server.Close()
+
+	authFlow := WebServerFlow{
+		ClientId:      "clientId",
+		ClientSecret:  "clientSecret",
+		RedirectUri:   "https://example.com/callback",
+		TokenEndpoint: server.URL,
+		CodeVerifier:  "verifier",
+		Code:          "code",
+	}
+
+	token, err := authFlow.NewToken(server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "accessToken1" {
+		t.Fatalf("unexpected access token %v", token.AccessToken)
+	}
+	if authFlow.GetRefreshToken() != "refreshToken" {
+		t.Fatalf("unexpected refresh token %v", authFlow.GetRefreshToken())
+	}
+
+	token, err = authFlow.RefreshToken(server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "accessToken2" {
+		t.Fatalf("unexpected access token %v", token.AccessToken)
+	}
+	if authFlow.GetRefreshToken() != "refreshToken" {
+		t.Fatal("expected refresh token to be retained")
+	}
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestWebServerDefaultEndpoints(t *testing.T) {
+	var requested string
+	httpClient := &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			requested = r.URL.String()
+			return nil, errors.New("not sent")
+		}),
+	}
+
+	authFlow := WebServerFlow{
+		ClientId: "clientId",
+		Code:     "code",
+	}
+	authorizationUrl, err := authFlow.AuthorizationUrl("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(authorizationUrl, ProductionAuthorizationEndpoint) {
+		t.Errorf("expected %v, actual %v", ProductionAuthorizationEndpoint, authorizationUrl)
+	}
+
+	authFlow.NewToken(httpClient)
+	if requested != ProductionTokenEndpoint {
+		t.Errorf("expected %v, actual %v", ProductionTokenEndpoint, requested)
+	}
+}
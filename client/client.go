@@ -4,21 +4,55 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/stackasaur/goforce/auth"
 	Req "github.com/stackasaur/goforce/shared/request"
 )
 
+// Client is safe for concurrent use by multiple goroutines. token refreshes
+// are serialized so that when many requests see an expired or rejected token
+// only one of them calls the auth flow and the rest reuse its result.
 type Client struct {
-	context    context.Context
-	httpClient *http.Client
-	authFlow   auth.AuthFlow
-	token      auth.Token
-	version    string
+	context        context.Context
+	httpClient     *http.Client
+	authFlow       auth.AuthFlow
+	tokenStore     auth.TokenStore
+	tokenKey       auth.TokenKey
+	retryPolicy    *RetryPolicy
+	apiLimitPolicy *ApiLimitPolicy
+	rateLimiter    *rateLimiter
+	// one value per request in flight when concurrency is capped
+	slots           chan struct{}
+	roundTrip       RoundTrip
+	instrumentation Instrumentation
+	logger          *slog.Logger
+	// see ClientConfig.GzipResponses and ClientConfig.GzipRequestsAbove
+	gzipResponses     bool
+	gzipRequestsAbove int
+	// see ClientConfig.KeepStoredToken
+	keepStoredToken bool
+
+	// guards token, version, closed, the cached identity, api usage and the
+	// org's versions
+	mu            sync.RWMutex
+	token         auth.Token
+	version       string
+	versions      []int
+	closed        bool
+	identity      *Identity
+	identityToken string
+	apiUsage      ApiUsage
+	// when a request was last let through to refresh stale api usage
+	apiUsageProbe time.Time
+	// holds a value while refreshing the token, a channel rather than a
+	// mutex so waiting can be abandoned when the context is done
+	refreshing chan struct{}
 }
 
 func (client *Client) GetHttpClient() *http.Client {
@@ -28,59 +62,217 @@ func (client *Client) GetContext() context.Context {
 	return client.context
 }
 func (client *Client) GetVersion() string {
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+
 	return client.version
 }
 func (client *Client) SetVersion(
 	version int,
 ) error {
-	if version == 0 || !validateVersion(version) {
+	if version == 0 || !client.supportsVersion(version) {
 		return ErrVersion
 	}
+	client.mu.Lock()
 	client.version = toVersionString(version)
+	client.mu.Unlock()
 
 	return nil
 }
 
 func (client *Client) GetUserId() string {
-	userId := client.token.Id
+	userId := client.getToken().Id
 	if len(userId) == 0 {
 		return ""
 	}
-	splt := strings.Split(client.token.Id, "/")
+	splt := strings.Split(userId, "/")
 
 	return splt[len(splt)-1]
 
 }
 
+// sends the request using the context the client was created with.
 func (client *Client) Send(
 	req Req.SfdcRequest,
 ) (*http.Response, error) {
-	httpClient := client.GetHttpClient()
+	return client.SendContext(
+		client.GetContext(),
+		req,
+	)
+}
 
-	token := client.token
+// sends the request, cancelling ctx aborts both the call and any token refresh
+// it triggers. transient failures are retried according to the client's
+// RetryPolicy.
+func (client *Client) SendContext(
+	ctx context.Context,
+	req Req.SfdcRequest,
+) (*http.Response, error) {
+	if client.instrumentation == nil {
+		return client.send(ctx, req, new(int))
+	}
+
+	ctx, end := client.instrumentation.StartRequest(
+		ctx,
+		newRequestInfo(req, client.GetVersion()),
+	)
+	start := time.Now()
+	attempts := 0
+	httpResponse, err := client.send(ctx, req, &attempts)
+
+	result := RequestResult{
+		Attempts: attempts,
+		Duration: time.Since(start),
+		Err:      err,
+	}
+	if httpResponse != nil {
+		result.StatusCode = httpResponse.StatusCode
+		if httpResponse.StatusCode >= 400 {
+			apiErrors := peekApiErrors(httpResponse)
+			if len(apiErrors) > 0 {
+				result.ErrorCode = apiErrors[0].ErrorCode
+			}
+		}
+	}
+	result.ApiUsage, _ = client.ApiUsage()
+	end(result)
+
+	return httpResponse, err
+}
+
+// sends with retries, counting attempts made.
+func (client *Client) send(
+	ctx context.Context,
+	req Req.SfdcRequest,
+	attempts *int,
+) (*http.Response, error) {
+	err := client.checkRequestVersion(req)
+	if err != nil {
+		return nil, err
+	}
+	err = client.checkApiUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := client.retryPolicy
+	if policy == nil || policy.MaxAttempts < 2 {
+		*attempts = 1
+		return client.sendOnce(ctx, req)
+	}
+	method, err := req.GetMethod()
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		*attempts = attempt
+		httpResponse, err := client.sendOnce(ctx, req)
+		if attempt >= policy.MaxAttempts ||
+			!policy.shouldRetry(ctx, method, httpResponse, err) {
+			return httpResponse, err
+		}
+
+		backoff := policy.backoff(attempt, httpResponse)
+		attrs := []slog.Attr{
+			slog.Int("attempt", attempt),
+			slog.Duration("backoff", backoff),
+		}
+		if err != nil {
+			attrs = append(attrs, slog.Any("error", err))
+		}
+		if httpResponse != nil {
+			attrs = append(attrs, slog.Int("status", httpResponse.StatusCode))
+		}
+		client.logger.LogAttrs(ctx, slog.LevelInfo, "retrying request", attrs...)
+		if httpResponse != nil {
+			httpResponse.Body.Close()
+		}
+		err = sleepContext(ctx, backoff)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// a single attempt, including the refresh and resend on 401.
+func (client *Client) sendOnce(
+	ctx context.Context,
+	req Req.SfdcRequest,
+) (*http.Response, error) {
+	token, closed := client.getTokenState()
+	if closed {
+		return nil, ErrClosed
+	}
 
 	if !token.Expiration.After(time.Now()) {
 		var err error
-		token, err = client.authFlow.RefreshToken(
-			httpClient,
+		token, err = client.refreshToken(
+			ctx,
+			token,
+		)
+		if err != nil {
+			return nil, errors.Join(
+				ErrToken,
+				err,
+			)
+		}
+	}
+
+	httpRequest, err := client.buildRequest(ctx, req, token)
+	if err != nil {
+		return nil, err
+	}
+
+	httpResponse, err := client.do(ctx, req, httpRequest)
+	if err != nil {
+		return nil, err
+	}
+	if httpResponse.StatusCode == 401 {
+		// refresh token and try again. the request is rebuilt as its body
+		// has been consumed and the instance may have changed.
+		httpResponse.Body.Close()
+
+		token, err = client.refreshToken(
+			ctx,
+			token,
 		)
-		client.token = token
 		if err != nil {
 			return nil, errors.Join(
 				ErrToken,
 				err,
 			)
 		}
+		httpRequest, err = client.buildRequest(ctx, req, token)
+		if err != nil {
+			return nil, err
+		}
+
+		httpResponse, err = client.do(ctx, req, httpRequest)
+		if err != nil {
+			return nil, err
+		}
 	}
+
+	return httpResponse, nil
+}
+
+// builds an authorized http request against the token's instance.
+func (client *Client) buildRequest(
+	ctx context.Context,
+	req Req.SfdcRequest,
+	token auth.Token,
+) (*http.Request, error) {
 	baseUrl, err := url.Parse(token.InstanceUrl)
 	if err != nil {
 		return nil, err
 	}
 
-	httpRequest, err := Req.SfdcRequestAsHttpRequest(
+	httpRequest, err := Req.SfdcRequestAsHttpRequestWithContext(
+		ctx,
 		req,
 		baseUrl,
-		client.version,
+		client.GetVersion(),
 	)
 	if err != nil {
 		return nil, err
@@ -90,35 +282,183 @@ func (client *Client) Send(
 		"Authorization",
 		fmt.Sprintf("Bearer %v", token.AccessToken),
 	)
+	if client.gzipRequestsAbove > 0 {
+		err = compressRequest(httpRequest, client.gzipRequestsAbove)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if client.gzipResponses && len(httpRequest.Header.Get("Accept-Encoding")) == 0 {
+		httpRequest.Header.Set("Accept-Encoding", "gzip")
+	}
 
-	httpResponse, err := httpClient.Do(httpRequest)
-	if err != nil {
-		return nil, err
+	return httpRequest, nil
+}
+
+// ends the session: the token is revoked when the auth flow implements
+// auth.Revoker, removed from the token store and cleared. flows holding a
+// refresh token revoke it too, ending every session issued from it, unless
+// KeepRefreshToken is set on the flow. a stored token is revoked for every
+// client sharing it, set ClientConfig.KeepStoredToken to leave it alone.
+//
+// flows wrapping a session that belongs to another program, such as
+// auth.SessionFlow, only revoke it when configured to. the client cannot be
+// used after Close; the token is cleared even if revocation fails.
+func (client *Client) Close() error {
+	client.refreshing <- struct{}{}
+	defer func() { <-client.refreshing }()
+
+	client.mu.Lock()
+	token := client.token
+	client.token = auth.Token{}
+	client.closed = true
+	client.mu.Unlock()
+
+	if client.tokenStore != nil {
+		if client.keepStoredToken {
+			return nil
+		}
+		client.invalidateStoredToken(client.GetContext())
 	}
-	if httpResponse.StatusCode == 401 {
-		// refresh token and try again
 
-		var err error
-		token, err = client.authFlow.RefreshToken(
-			httpClient,
-		)
+	revoker, ok := client.authFlow.(auth.Revoker)
+	if !ok || len(token.AccessToken) == 0 {
+		return nil
+	}
+	return revoker.Revoke(
+		withContext(
+			client.GetContext(),
+			client.GetHttpClient(),
+		),
+		token,
+	)
+}
+
+func (client *Client) getToken() auth.Token {
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+
+	return client.token
+}
+func (client *Client) getTokenState() (auth.Token, bool) {
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+
+	return client.token, client.closed
+}
+func (client *Client) setToken(
+	token auth.Token,
+) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	client.token = token
+}
+
+// replaces a stale token. refreshes are serialized, if the token was already
+// replaced while waiting the current one is returned. when a token store is
+// configured another process may already have refreshed, so a newer stored
+// token is used before falling back to the auth flow, and a stored copy of the
+// stale token is invalidated. the refreshed token is saved for other
+// processes; a failure to save does not fail the request as the token itself
+// is valid.
+func (client *Client) refreshToken(
+	ctx context.Context,
+	stale auth.Token,
+) (auth.Token, error) {
+	select {
+	case client.refreshing <- struct{}{}:
+		defer func() { <-client.refreshing }()
+	case <-ctx.Done():
+		return auth.Token{}, ctx.Err()
+	}
+
+	current, closed := client.getTokenState()
+	if closed {
+		return auth.Token{}, ErrClosed
+	}
+	if current.AccessToken != stale.AccessToken &&
+		current.Expiration.After(time.Now()) {
+		return current, nil
+	}
 
+	if client.tokenStore != nil {
+		stored, ok, err := client.tokenStore.Load(client.tokenKey)
 		if err != nil {
-			return nil, errors.Join(
-				ErrToken,
-				err,
+			client.logger.WarnContext(
+				ctx,
+				"loading stored token failed",
+				"error", err,
 			)
 		}
-		client.token = token
-		httpRequest.Header.Set(
-			"Authorization",
-			fmt.Sprintf("Bearer %v", token.AccessToken),
+		if err == nil && ok {
+			if stored.AccessToken == stale.AccessToken {
+				client.invalidateStoredToken(ctx)
+			} else if stored.Expiration.After(time.Now()) {
+				client.logger.DebugContext(
+					ctx,
+					"using stored token",
+					"token", stored,
+				)
+				useStoredToken(client.authFlow, stored)
+				client.setToken(stored)
+				return stored, nil
+			}
+		}
+	}
+
+	client.logger.DebugContext(
+		ctx,
+		"refreshing token",
+		"flow", logFlow(client.authFlow),
+	)
+	token, err := client.authFlow.RefreshToken(
+		withContext(
+			ctx,
+			client.GetHttpClient(),
+		),
+	)
+	if err != nil {
+		client.logger.ErrorContext(
+			ctx,
+			"token refresh failed",
+			"error", err,
 		)
+		return auth.Token{}, err
+	}
+	client.logger.InfoContext(
+		ctx,
+		"token refreshed",
+		"token", token,
+	)
+	client.setToken(token)
 
-		return httpClient.Do(httpRequest)
+	if client.tokenStore != nil {
+		err = client.tokenStore.Save(client.tokenKey, token)
+		if err != nil {
+			client.logger.WarnContext(
+				ctx,
+				"saving token failed",
+				"error", err,
+			)
+		}
 	}
+	return token, nil
+}
 
-	return httpResponse, nil
+// removes the token from the store, a failure is logged as the token is
+// replaced or discarded by the caller either way.
+func (client *Client) invalidateStoredToken(
+	ctx context.Context,
+) {
+	err := client.tokenStore.Invalidate(client.tokenKey)
+	if err != nil {
+		client.logger.WarnContext(
+			ctx,
+			"invalidating stored token failed",
+			"error", err,
+		)
+	}
 }
 
 type ClientConfig struct {
@@ -126,6 +466,43 @@ type ClientConfig struct {
 	Context    context.Context
 	AuthFlow   auth.AuthFlow
 	Version    int
+	// optional store consulted before logging in and updated after every
+	// refresh. TokenKey is required when a store is set.
+	TokenStore auth.TokenStore
+	TokenKey   auth.TokenKey
+	// leave the stored token unrevoked and in the store on Close, so other
+	// clients sharing it keep their session.
+	KeepStoredToken bool
+	// optional, requests are not retried when nil. see DefaultRetryPolicy.
+	RetryPolicy *RetryPolicy
+	// optional, api usage is still tracked when nil. see Client.ApiUsage.
+	ApiLimitPolicy *ApiLimitPolicy
+	// requests per second sent by the client, with bursts of up to
+	// RateBurst. zero disables rate limiting.
+	RateLimit float64
+	RateBurst int
+	// requests in flight at once, a request counts until its response body
+	// is closed. zero means unlimited.
+	MaxConcurrentRequests int
+	// wrap every http call, the first middleware is outermost.
+	Middlewares []Middleware
+	// optional hook for tracing and metrics, see Instrumentation.
+	Instrumentation Instrumentation
+	// optional, receives request, response and token events. credentials
+	// are always redacted. nothing is logged when nil.
+	Logger *slog.Logger
+	// fetch the org's api versions when the client is created. a zero
+	// Version then selects the org's latest, and versions are validated
+	// against the org instead of MinSupportedVersion and
+	// MaxSupportedVersion.
+	DiscoverVersions bool
+	// ask for gzip encoded responses and decompress them. http.Transport
+	// already does this unless Accept-Encoding is set by hand, enabling it
+	// keeps responses compressed through custom transports and middlewares.
+	GzipResponses bool
+	// request bodies larger than this many bytes are sent gzip encoded.
+	// zero sends every body uncompressed.
+	GzipRequestsAbove int
 }
 
 func NewClient(
@@ -143,7 +520,7 @@ func NewClient(
 	if version == 0 {
 		version = DefaultVersion
 	}
-	if !validateVersion(version) {
+	if !config.DiscoverVersions && !validateVersion(version) {
 		return nil, ErrVersion
 	}
 
@@ -151,28 +528,167 @@ func NewClient(
 		return nil, ErrAuthFlow
 	}
 
-	authFlow := config.AuthFlow
-	token, err := authFlow.NewToken(
-		httpClient,
+	if config.TokenStore != nil && config.TokenKey == (auth.TokenKey{}) {
+		return nil, ErrTokenKey
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = discardLogger()
+	}
+
+	token, err := newToken(
+		ctx,
+		config,
+		withContext(ctx, httpClient),
+		logger,
 	)
 	if err != nil {
+		logger.ErrorContext(
+			ctx,
+			"login failed",
+			"error", err,
+		)
 		return nil, errors.Join(
 			ErrToken,
 			err,
 		)
 	}
 
+	var limiter *rateLimiter
+	if config.RateLimit > 0 {
+		limiter = newRateLimiter(config.RateLimit, config.RateBurst)
+	}
+	var slots chan struct{}
+	if config.MaxConcurrentRequests > 0 {
+		slots = make(chan struct{}, config.MaxConcurrentRequests)
+	}
+
 	client := Client{
-		context:    ctx,
-		httpClient: httpClient,
-		version:    toVersionString(version),
-		authFlow:   config.AuthFlow,
-		token:      token,
+		context:           ctx,
+		httpClient:        httpClient,
+		version:           toVersionString(version),
+		authFlow:          config.AuthFlow,
+		token:             token,
+		tokenStore:        config.TokenStore,
+		tokenKey:          config.TokenKey,
+		keepStoredToken:   config.KeepStoredToken,
+		retryPolicy:       config.RetryPolicy,
+		apiLimitPolicy:    config.ApiLimitPolicy,
+		rateLimiter:       limiter,
+		slots:             slots,
+		roundTrip:         chain(httpClient, config.Middlewares),
+		instrumentation:   config.Instrumentation,
+		logger:            logger,
+		gzipResponses:     config.GzipResponses,
+		gzipRequestsAbove: config.GzipRequestsAbove,
+		refreshing:        make(chan struct{}, 1),
+	}
+
+	if config.DiscoverVersions {
+		err = client.discoverVersion(ctx, config.Version)
+		if err != nil {
+			// the client is not returned, so end the session it holds
+			return nil, errors.Join(
+				err,
+				client.Close(),
+			)
+		}
 	}
 
 	return &client, nil
 }
 
+// implemented by flows that wait on the user to log in, such as
+// auth.DeviceFlow, so the wait ends with the context.
+type contextLogin interface {
+	NewTokenContext(
+		ctx context.Context,
+		httpClient *http.Client,
+	) (auth.Token, error)
+}
+
+// reuses an unexpired stored token when possible, otherwise logs in and saves
+// the new token.
+func newToken(
+	ctx context.Context,
+	config ClientConfig,
+	httpClient *http.Client,
+	logger *slog.Logger,
+) (auth.Token, error) {
+	store := config.TokenStore
+	if store != nil {
+		stored, ok, err := store.Load(config.TokenKey)
+		if err != nil {
+			logger.WarnContext(
+				ctx,
+				"loading stored token failed",
+				"error", err,
+			)
+		}
+		if err == nil && ok && stored.Expiration.After(time.Now()) {
+			logger.DebugContext(
+				ctx,
+				"using stored token",
+				"token", stored,
+			)
+			useStoredToken(config.AuthFlow, stored)
+			return stored, nil
+		}
+	}
+
+	logger.DebugContext(
+		ctx,
+		"logging in",
+		"flow", logFlow(config.AuthFlow),
+	)
+	var token auth.Token
+	var err error
+	if authFlow, ok := config.AuthFlow.(contextLogin); ok {
+		token, err = authFlow.NewTokenContext(
+			ctx,
+			httpClient,
+		)
+	} else {
+		token, err = config.AuthFlow.NewToken(
+			httpClient,
+		)
+	}
+	if err != nil {
+		return auth.Token{}, err
+	}
+	logger.InfoContext(
+		ctx,
+		"logged in",
+		"token", token,
+	)
+	if store != nil {
+		err = store.Save(config.TokenKey, token)
+		if err != nil {
+			logger.WarnContext(
+				ctx,
+				"saving token failed",
+				"error", err,
+			)
+		}
+	}
+	return token, nil
+}
+
+// hands the refresh token of a stored token to the auth flow, so a token
+// another process logged in with can still be refreshed.
+func useStoredToken(
+	authFlow auth.AuthFlow,
+	stored auth.Token,
+) {
+	setter, ok := authFlow.(auth.RefreshTokenSetter)
+	if ok && len(stored.RefreshToken) > 0 {
+		setter.SetRefreshToken(stored.RefreshToken)
+	}
+}
+
 var ErrToken = errors.New("error getting token")
 var ErrVersion = errors.New("invalid version")
 var ErrAuthFlow = errors.New("invalid auth flow")
+var ErrTokenKey = errors.New("token key required with token store")
+var ErrClosed = errors.New("client is closed")
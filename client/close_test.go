@@ -0,0 +1,108 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stackasaur/goforce/auth"
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+// counting flow that records revoked access tokens.
+type revokingFlow struct {
+	countingFlow
+	revoked []string
+}
+
+func (flow *revokingFlow) Revoke(
+	_ *http.Client,
+	token auth.Token,
+) error {
+	flow.revoked = append(flow.revoked, token.AccessToken)
+	return nil
+}
+
+func TestClientClose(t *testing.T) {
+	store := auth.NewMemoryTokenStore()
+	key := auth.TokenKey{
+		OrgId:    "00D000000000001",
+		Username: "user@example.com",
+	}
+	authFlow := &revokingFlow{
+		countingFlow: countingFlow{
+			instanceUrl: "https://example.my.salesforce.com",
+		},
+	}
+
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			AuthFlow:   authFlow,
+			TokenStore: store,
+			TokenKey:   key,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = sfdcClient.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(authFlow.revoked) != 1 || authFlow.revoked[0] != "token1" {
+		t.Fatalf("unexpected revoked tokens %v", authFlow.revoked)
+	}
+	_, ok, _ := store.Load(key)
+	if ok {
+		t.Fatal("expected stored token to be invalidated")
+	}
+
+	path, _ := url.Parse("/services/data/v60.0/")
+	_, err = sfdcClient.Send(Req.GenericRequest{
+		Method: http.MethodGet,
+		Path:   path,
+	})
+	if !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, received %v", err)
+	}
+}
+
+func TestClientCloseKeepStoredToken(t *testing.T) {
+	store := auth.NewMemoryTokenStore()
+	key := auth.TokenKey{
+		OrgId:    "00D000000000001",
+		Username: "user@example.com",
+	}
+	authFlow := &revokingFlow{
+		countingFlow: countingFlow{
+			instanceUrl: "https://example.my.salesforce.com",
+		},
+	}
+
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			AuthFlow:        authFlow,
+			TokenStore:      store,
+			TokenKey:        key,
+			KeepStoredToken: true,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = sfdcClient.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// other clients sharing the store keep using the token
+	if len(authFlow.revoked) != 0 {
+		t.Fatalf("expected %v, actual %v", 0, authFlow.revoked)
+	}
+	stored, ok, _ := store.Load(key)
+	if !ok || stored.AccessToken != "token1" {
+		t.Fatalf("expected %v, actual %v", "token1", stored.AccessToken)
+	}
+}
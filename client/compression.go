@@ -0,0 +1,98 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// gzips the body of httpRequest when it is larger than threshold bytes.
+func compressRequest(
+	httpRequest *http.Request,
+	threshold int,
+) error {
+	if httpRequest.Body == nil ||
+		httpRequest.ContentLength <= int64(threshold) ||
+		len(httpRequest.Header.Get("Content-Encoding")) > 0 {
+		return nil
+	}
+
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	_, err := io.Copy(writer, httpRequest.Body)
+	if err != nil {
+		return errors.Join(
+			ErrCompression,
+			err,
+		)
+	}
+	err = writer.Close()
+	if err != nil {
+		return errors.Join(
+			ErrCompression,
+			err,
+		)
+	}
+	httpRequest.Body.Close()
+
+	compressed := buffer.Bytes()
+	httpRequest.Body = io.NopCloser(bytes.NewReader(compressed))
+	httpRequest.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(compressed)), nil
+	}
+	httpRequest.ContentLength = int64(len(compressed))
+	httpRequest.Header.Set("Content-Encoding", "gzip")
+
+	return nil
+}
+
+// replaces a gzip encoded response body with its decompressed content. the
+// response is left untouched when it is not gzip encoded. an empty body, as
+// sent with 204, 304 or HEAD, stays empty. the body is closed on error.
+func decompressResponse(
+	httpResponse *http.Response,
+) error {
+	if !strings.EqualFold(httpResponse.Header.Get("Content-Encoding"), "gzip") {
+		return nil
+	}
+
+	reader, err := gzip.NewReader(httpResponse.Body)
+	switch {
+	case errors.Is(err, io.EOF):
+		httpResponse.Body.Close()
+		httpResponse.Body = http.NoBody
+	case err != nil:
+		httpResponse.Body.Close()
+		return errors.Join(
+			ErrCompression,
+			err,
+		)
+	default:
+		httpResponse.Body = &gzipBody{
+			Reader: reader,
+			body:   httpResponse.Body,
+		}
+	}
+	httpResponse.Header.Del("Content-Encoding")
+	httpResponse.Header.Del("Content-Length")
+	httpResponse.ContentLength = -1
+	httpResponse.Uncompressed = true
+
+	return nil
+}
+
+// reads the decompressed content, closing closes the underlying body.
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (body *gzipBody) Close() error {
+	body.Reader.Close()
+	return body.body.Close()
+}
+
+var ErrCompression = errors.New("error compressing or decompressing payload")
@@ -0,0 +1,197 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stackasaur/goforce/auth"
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+func TestCompression(t *testing.T) {
+	var contentEncodings []string
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Accept-Encoding") != "gzip" {
+				t.Errorf("expected %v, actual %v", "gzip", r.Header.Get("Accept-Encoding"))
+			}
+			contentEncoding := r.Header.Get("Content-Encoding")
+			contentEncodings = append(contentEncodings, contentEncoding)
+
+			var reader io.Reader = r.Body
+			if contentEncoding == "gzip" {
+				gzipReader, err := gzip.NewReader(r.Body)
+				if err != nil {
+					t.Errorf("invalid gzip body: %v", err)
+					return
+				}
+				reader = gzipReader
+			}
+			body, _ := io.ReadAll(reader)
+			bodies = append(bodies, string(body))
+
+			w.Header().Set("Content-Encoding", "gzip")
+			writer := gzip.NewWriter(w)
+			writer.Write([]byte(`{"records":[]}`))
+			writer.Close()
+		},
+	))
+	defer server.Close()
+
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow: &auth.SessionFlow{
+				AccessToken: "accessToken",
+				InstanceUrl: server.URL,
+			},
+			GzipResponses:     true,
+			GzipRequestsAbove: 16,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := url.Parse("/services/data/v60.0/composite")
+	large := strings.Repeat("a", 64)
+	for _, body := range []string{"small", large} {
+		res, err := sfdcClient.Send(Req.GenericRequest{
+			Method: http.MethodPost,
+			Path:   path,
+			Body:   []byte(body),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		responseBody, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+
+		if string(responseBody) != `{"records":[]}` {
+			t.Errorf("expected %v, actual %v", `{"records":[]}`, string(responseBody))
+		}
+		if len(res.Header.Get("Content-Encoding")) > 0 {
+			t.Errorf("expected %v, actual %v", "", res.Header.Get("Content-Encoding"))
+		}
+	}
+
+	expected := []string{"", "gzip"}
+	for i := range expected {
+		if contentEncodings[i] != expected[i] {
+			t.Errorf("expected %v, actual %v", expected[i], contentEncodings[i])
+		}
+	}
+	if bodies[0] != "small" || bodies[1] != large {
+		t.Errorf("expected %v, actual %v", []string{"small", large}, bodies)
+	}
+}
+
+func TestCompressRequestResend(t *testing.T) {
+	body := bytes.Repeat([]byte("a"), 4096)
+	httpRequest, err := http.NewRequest(
+		http.MethodPost,
+		"https://example.com",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = compressRequest(httpRequest, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a retried request reads the body again through GetBody
+	resent, err := httpRequest.GetBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := gzip.NewReader(resent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _ := io.ReadAll(reader)
+	if !bytes.Equal(actual, body) {
+		t.Errorf("expected %v, actual %v", string(body), string(actual))
+	}
+	if httpRequest.ContentLength >= int64(len(body)) {
+		t.Errorf("expected less than %v, actual %v", len(body), httpRequest.ContentLength)
+	}
+}
+
+func TestDecompressEmptyResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "gzip")
+			w.WriteHeader(http.StatusNoContent)
+		},
+	))
+	defer server.Close()
+
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow: &auth.SessionFlow{
+				AccessToken: "accessToken",
+				InstanceUrl: server.URL,
+			},
+			GzipResponses: true,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := url.Parse("/services/data/v60.0/sobjects/Account/001000000000001")
+	res, err := sfdcClient.Send(Req.GenericRequest{
+		Method: http.MethodPatch,
+		Path:   path,
+		Body:   []byte(`{"Name":"name"}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	responseBody, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		t.Errorf("expected %v, actual %v", http.StatusNoContent, res.StatusCode)
+	}
+	if len(responseBody) > 0 {
+		t.Errorf("expected %v, actual %v", "", string(responseBody))
+	}
+}
+
+func TestDecompressInvalidResponse(t *testing.T) {
+	body := &closeRecorder{Reader: strings.NewReader("not gzip")}
+	httpResponse := &http.Response{
+		Header: http.Header{"Content-Encoding": []string{"gzip"}},
+		Body:   body,
+	}
+	err := decompressResponse(httpResponse)
+	if !errors.Is(err, ErrCompression) {
+		t.Errorf("expected %v, actual %v", ErrCompression, err)
+	}
+	if !body.closed {
+		t.Errorf("expected %v, actual %v", true, body.closed)
+	}
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (body *closeRecorder) Close() error {
+	body.closed = true
+	return nil
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+func TestConcurrentRefresh(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer token2" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		},
+	))
+	defer server.Close()
+
+	authFlow := &countingFlow{
+		instanceUrl: server.URL,
+	}
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow:   authFlow,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := url.Parse("/services/data/v60.0/")
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := sfdcClient.Send(Req.GenericRequest{
+				Method: http.MethodGet,
+				Path:   path,
+			})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res.Body.Close()
+			if res.StatusCode != http.StatusNoContent {
+				t.Errorf("unexpected status %d", res.StatusCode)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// one login from NewClient and a single shared refresh
+	if authFlow.calls != 2 {
+		t.Fatalf("expected 2 auth calls, received %d", authFlow.calls)
+	}
+}
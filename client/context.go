@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// auth flows take an *http.Client rather than a context, so the context is
+// attached by the transport of a shallow copy of the client.
+func withContext(
+	ctx context.Context,
+	httpClient *http.Client,
+) *http.Client {
+	if ctx == nil || ctx.Done() == nil {
+		return httpClient
+	}
+	transport := httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	ret := *httpClient
+	ret.Transport = contextTransport{
+		ctx:       ctx,
+		transport: transport,
+	}
+	return &ret
+}
+
+type contextTransport struct {
+	ctx       context.Context
+	transport http.RoundTripper
+}
+
+func (t contextTransport) RoundTrip(
+	req *http.Request,
+) (*http.Response, error) {
+	return t.transport.RoundTrip(
+		req.WithContext(t.ctx),
+	)
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stackasaur/goforce/auth"
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+func TestSendContextCancellation(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		},
+	))
+	defer server.Close()
+	defer close(release)
+
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow: &auth.SessionFlow{
+				AccessToken: "accessToken",
+				InstanceUrl: server.URL,
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		50*time.Millisecond,
+	)
+	defer cancel()
+
+	path, _ := url.Parse("/services/data/v60.0/")
+	_, err = sfdcClient.SendContext(
+		ctx,
+		Req.GenericRequest{
+			Method: http.MethodGet,
+			Path:   path,
+		},
+	)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, received %v", err)
+	}
+}
+
+func TestNewClientContextCancellation(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		},
+	))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		50*time.Millisecond,
+	)
+	defer cancel()
+
+	_, err := NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			Context:    ctx,
+			AuthFlow: auth.ClientCredentialsFlow{
+				TokenEndpoint: server.URL,
+			},
+		},
+	)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, received %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+// urls returned by the identity service. salesforce leaves a literal
+// "{version}" placeholder in the api urls.
+type IdentityUrls struct {
+	Enterprise   string `json:"enterprise"`
+	Metadata     string `json:"metadata"`
+	Partner      string `json:"partner"`
+	Rest         string `json:"rest"`
+	SObjects     string `json:"sobjects"`
+	Search       string `json:"search"`
+	Query        string `json:"query"`
+	Recent       string `json:"recent"`
+	Profile      string `json:"profile"`
+	Feeds        string `json:"feeds"`
+	Groups       string `json:"groups"`
+	Users        string `json:"users"`
+	CustomDomain string `json:"custom_domain"`
+}
+
+// Identity describes the org and user a token was issued for.
+type Identity struct {
+	Id             string       `json:"id"`
+	OrganizationId string       `json:"organization_id"`
+	UserId         string       `json:"user_id"`
+	Username       string       `json:"username"`
+	DisplayName    string       `json:"display_name"`
+	Email          string       `json:"email"`
+	Locale         string       `json:"locale"`
+	Language       string       `json:"language"`
+	Timezone       string       `json:"timezone"`
+	UserType       string       `json:"user_type"`
+	Active         bool         `json:"active"`
+	Urls           IdentityUrls `json:"urls"`
+}
+
+// the openid userinfo endpoint names a few fields differently.
+type userInfo struct {
+	Identity
+	PreferredUsername string `json:"preferred_username"`
+	ZoneInfo          string `json:"zoneinfo"`
+	Name              string `json:"name"`
+}
+
+// fetches the identity of the current token from its identity url, falling
+// back to /services/oauth2/userinfo when the token has none. the result is
+// cached until the token changes.
+func (client *Client) Identity() (*Identity, error) {
+	return client.IdentityContext(
+		client.GetContext(),
+	)
+}
+
+func (client *Client) IdentityContext(
+	ctx context.Context,
+) (*Identity, error) {
+	token := client.getToken()
+
+	client.mu.RLock()
+	cached := client.identity
+	cachedFor := client.identityToken
+	client.mu.RUnlock()
+	if cached != nil && cachedFor == token.AccessToken {
+		ret := *cached
+		return &ret, nil
+	}
+
+	identityUrl := token.Id
+	if len(identityUrl) == 0 {
+		identityUrl = "/services/oauth2/userinfo"
+	}
+	path, err := url.Parse(identityUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := Req.Do[userInfo](
+		ctx,
+		client,
+		Req.GenericRequest{
+			Method: http.MethodGet,
+			Path:   path,
+			Headers: map[string]string{
+				"Accept": "application/json",
+			},
+		},
+		http.StatusOK,
+	)
+	if err != nil {
+		return nil, errors.Join(
+			ErrIdentity,
+			err,
+		)
+	}
+	info := res.Body
+	identity := info.Identity
+	if len(identity.Username) == 0 {
+		identity.Username = info.PreferredUsername
+	}
+	if len(identity.Timezone) == 0 {
+		identity.Timezone = info.ZoneInfo
+	}
+	if len(identity.DisplayName) == 0 {
+		identity.DisplayName = info.Name
+	}
+
+	// the request may have refreshed the token, cache against the one in use
+	client.mu.Lock()
+	client.identity = &identity
+	client.identityToken = client.token.AccessToken
+	client.mu.Unlock()
+
+	ret := identity
+	return &ret, nil
+}
+
+var ErrIdentity = errors.New("error getting identity")
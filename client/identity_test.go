@@ -0,0 +1,124 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stackasaur/goforce/auth"
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+// auth flow returning a fixed token.
+type staticFlow struct {
+	token auth.Token
+}
+
+func (flow staticFlow) NewToken(
+	_ *http.Client,
+) (auth.Token, error) {
+	return flow.token, nil
+}
+func (flow staticFlow) RefreshToken(
+	_ *http.Client,
+) (auth.Token, error) {
+	return flow.token, nil
+}
+
+func TestIdentity(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if r.URL.Path != "/id/00D000000000001/005000000000001" {
+				t.Errorf("unexpected path %s", r.URL.Path)
+			}
+			w.Write([]byte(`{
+				"id": "https://login.salesforce.com/id/00D000000000001/005000000000001",
+				"organization_id": "00D000000000001",
+				"user_id": "005000000000001",
+				"username": "user@example.com",
+				"locale": "en_US",
+				"timezone": "America/Los_Angeles",
+				"urls": {
+					"rest": "https://example.my.salesforce.com/services/data/v{version}/",
+					"metadata": "https://example.my.salesforce.com/services/Soap/m/{version}/00D000000000001"
+				}
+			}`))
+		},
+	))
+	defer server.Close()
+
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow: staticFlow{
+				token: auth.Token{
+					Id:          server.URL + "/id/00D000000000001/005000000000001",
+					AccessToken: "accessToken",
+					InstanceUrl: server.URL,
+					Expiration:  time.Now().Add(time.Hour),
+				},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identity, err := sfdcClient.Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.OrganizationId != "00D000000000001" ||
+		identity.Username != "user@example.com" ||
+		identity.Timezone != "America/Los_Angeles" ||
+		identity.Urls.Rest == "" {
+		t.Fatalf("unexpected identity %+v", identity)
+	}
+
+	_, err = sfdcClient.Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Fatalf("expected identity to be cached, received %d requests", requests)
+	}
+}
+
+func TestIdentityError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`[{"errorCode":"INSUFFICIENT_ACCESS_OR_READONLY","message":"no access"}]`))
+		},
+	))
+	defer server.Close()
+
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow: &auth.SessionFlow{
+				AccessToken: "accessToken",
+				InstanceUrl: server.URL,
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = sfdcClient.Identity()
+	if !errors.Is(err, ErrIdentity) {
+		t.Errorf("expected %v, actual %v", ErrIdentity, err)
+	}
+	if !errors.Is(err, Req.ErrInsufficientAccess) {
+		t.Errorf("expected %v, actual %v", Req.ErrInsufficientAccess, err)
+	}
+	var responseError *Req.ResponseError
+	if !errors.As(err, &responseError) || responseError.StatusCode != http.StatusForbidden {
+		t.Errorf("expected %v, actual %v", http.StatusForbidden, err)
+	}
+}
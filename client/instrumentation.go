@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+// Instrumentation observes every call to Client.Send. StartRequest is called
+// before the first attempt and may return a derived context (e.g. carrying a
+// span) used for the attempts; the returned function is called once the
+// request, including retries, completes. see the otel module for an
+// opentelemetry implementation.
+type Instrumentation interface {
+	StartRequest(
+		ctx context.Context,
+		info RequestInfo,
+	) (context.Context, func(RequestResult))
+}
+
+// RequestInfo describes a request before it is sent. PathTemplate has the api
+// version, record ids and query locators replaced by placeholders so it can be
+// used as a low cardinality span name or metric attribute.
+type RequestInfo struct {
+	Method       string
+	PathTemplate string
+	SObject      string
+}
+
+// RequestResult describes the outcome of a request. StatusCode is zero and Err
+// set when no response was received. ErrorCode is the first ApiError.ErrorCode
+// of an error response. ApiUsage is the latest known usage, zero if unknown.
+type RequestResult struct {
+	StatusCode int
+	ErrorCode  string
+	Attempts   int
+	Duration   time.Duration
+	Err        error
+	ApiUsage   ApiUsage
+}
+
+var versionSegment = regexp.MustCompile(`^v\d+\.\d+$`)
+var recordIdSegment = regexp.MustCompile(`^[a-zA-Z0-9]{15}([a-zA-Z0-9]{3})?$`)
+var digits = regexp.MustCompile(`\d`)
+
+func newRequestInfo(
+	req Req.SfdcRequest,
+	version string,
+) RequestInfo {
+	var info RequestInfo
+	info.Method, _ = req.GetMethod()
+
+	path, err := req.GetPath(version)
+	if err != nil || path == nil {
+		return info
+	}
+	info.PathTemplate, info.SObject = pathTemplate(path.Path)
+
+	return info
+}
+
+// e.g. /services/data/v60.0/sobjects/Account/001000000000001 becomes
+// /services/data/v{version}/sobjects/Account/{id} with sobject Account.
+func pathTemplate(
+	path string,
+) (string, string) {
+	var sobject string
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if i == 0 {
+			continue
+		}
+		previous := segments[i-1]
+		switch {
+		case versionSegment.MatchString(segment):
+			segments[i] = "v{version}"
+		case previous == "sobjects":
+			sobject = segment
+		case previous == "query" || previous == "queryAll":
+			segments[i] = "{locator}"
+		case recordIdSegment.MatchString(segment) &&
+			digits.MatchString(segment):
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/"), sobject
+}
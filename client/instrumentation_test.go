@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stackasaur/goforce/auth"
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+type recordingInstrumentation struct {
+	infos   []RequestInfo
+	results []RequestResult
+}
+
+func (instrumentation *recordingInstrumentation) StartRequest(
+	ctx context.Context,
+	info RequestInfo,
+) (context.Context, func(RequestResult)) {
+	instrumentation.infos = append(instrumentation.infos, info)
+	return ctx, func(result RequestResult) {
+		instrumentation.results = append(instrumentation.results, result)
+	}
+}
+
+func TestInstrumentation(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.Header().Set("Sforce-Limit-Info", "api-usage=10/100")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`[{"errorCode":"UNABLE_TO_LOCK_ROW","message":"locked"}]`))
+		},
+	))
+	defer server.Close()
+
+	instrumentation := &recordingInstrumentation{}
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow: &auth.SessionFlow{
+				AccessToken: "accessToken",
+				InstanceUrl: server.URL,
+			},
+			RetryPolicy:     testRetryPolicy(),
+			Instrumentation: instrumentation,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := url.Parse("/services/data/v60.0/sobjects/Account/001000000000001AAA")
+	res, err := sfdcClient.Send(Req.GenericRequest{
+		Method: http.MethodPatch,
+		Path:   path,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if len(body) == 0 {
+		t.Fatal("expected error body to remain readable")
+	}
+
+	if len(instrumentation.infos) != 1 || len(instrumentation.results) != 1 {
+		t.Fatalf(
+			"expected one request, received %v %v",
+			instrumentation.infos,
+			instrumentation.results,
+		)
+	}
+	info := instrumentation.infos[0]
+	if info.Method != http.MethodPatch ||
+		info.PathTemplate != "/services/data/v{version}/sobjects/Account/{id}" ||
+		info.SObject != "Account" {
+		t.Fatalf("unexpected info %+v", info)
+	}
+	result := instrumentation.results[0]
+	if result.StatusCode != 400 ||
+		result.ErrorCode != "UNABLE_TO_LOCK_ROW" ||
+		result.Attempts != 4 ||
+		result.ApiUsage.Used != 10 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestPathTemplate(t *testing.T) {
+	tests := []struct {
+		path     string
+		template string
+		sobject  string
+	}{
+		{
+			"/services/data/v60.0/sobjects/Account/001000000000001/",
+			"/services/data/v{version}/sobjects/Account/{id}/",
+			"Account",
+		},
+		{
+			"/services/data/v60.0/query/01gD0000002HU6KIAW-2000",
+			"/services/data/v{version}/query/{locator}",
+			"",
+		},
+		{
+			"/services/data/v60.0/composite",
+			"/services/data/v{version}/composite",
+			"",
+		},
+	}
+	for _, test := range tests {
+		template, sobject := pathTemplate(test.path)
+		if template != test.template || sobject != test.sobject {
+			t.Fatalf(
+				"expected %v %v, actual %v %v",
+				test.template,
+				test.sobject,
+				template,
+				sobject,
+			)
+		}
+	}
+}
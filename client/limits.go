@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ApiUsage is the org's daily api usage as last reported by salesforce in the
+// Sforce-Limit-Info response header.
+type ApiUsage struct {
+	Used      int
+	Max       int
+	UpdatedAt time.Time
+}
+
+func (usage ApiUsage) Remaining() int {
+	return usage.Max - usage.Used
+}
+
+// fraction of the daily limit used, between 0 and 1.
+func (usage ApiUsage) Fraction() float64 {
+	if usage.Max <= 0 {
+		return 0
+	}
+	return float64(usage.Used) / float64(usage.Max)
+}
+
+// ApiLimitPolicy reacts to the api usage reported on every response. the
+// fractions are of the org's daily limit, a zero value disables the behavior.
+type ApiLimitPolicy struct {
+	// OnThreshold is called when usage rises to or above one of Thresholds.
+	// each threshold fires once until usage drops below it again.
+	Thresholds  []float64
+	OnThreshold func(usage ApiUsage, threshold float64)
+	// requests are delayed by ThrottleDelay once usage reaches ThrottleAbove.
+	ThrottleAbove float64
+	ThrottleDelay time.Duration
+	// requests fail with ErrApiLimit once usage reaches RefuseAbove. usage
+	// is only reported on responses, so once it is older than MaxUsageAge
+	// one request is let through to refresh it. MaxUsageAge defaults to
+	// DefaultMaxUsageAge.
+	RefuseAbove float64
+	MaxUsageAge time.Duration
+}
+
+const DefaultMaxUsageAge = 5 * time.Minute
+
+// returns the last reported api usage, false until a response carried it.
+func (client *Client) ApiUsage() (ApiUsage, bool) {
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+
+	return client.apiUsage, !client.apiUsage.UpdatedAt.IsZero()
+}
+
+// parses the api-usage entry of a Sforce-Limit-Info header, e.g.
+// "api-usage=18/5000, per-app-api-usage=17/250(appName=sample-app)".
+func parseLimitInfo(
+	header string,
+) (ApiUsage, bool) {
+	for _, entry := range strings.Split(header, ",") {
+		value, ok := strings.CutPrefix(
+			strings.TrimSpace(entry),
+			"api-usage=",
+		)
+		if !ok {
+			continue
+		}
+		usedPart, maxPart, ok := strings.Cut(value, "/")
+		if !ok {
+			return ApiUsage{}, false
+		}
+		used, err := strconv.Atoi(usedPart)
+		if err != nil {
+			return ApiUsage{}, false
+		}
+		limit, err := strconv.Atoi(maxPart)
+		if err != nil {
+			return ApiUsage{}, false
+		}
+		return ApiUsage{
+			Used: used,
+			Max:  limit,
+		}, true
+	}
+	return ApiUsage{}, false
+}
+
+// records the usage reported by a response and fires crossed thresholds.
+func (client *Client) trackApiUsage(
+	httpResponse *http.Response,
+) {
+	usage, ok := parseLimitInfo(
+		httpResponse.Header.Get("Sforce-Limit-Info"),
+	)
+	if !ok {
+		return
+	}
+	usage.UpdatedAt = time.Now()
+
+	client.mu.Lock()
+	previous := client.apiUsage
+	client.apiUsage = usage
+	client.mu.Unlock()
+
+	policy := client.apiLimitPolicy
+	if policy == nil || policy.OnThreshold == nil {
+		return
+	}
+	for _, threshold := range policy.Thresholds {
+		if previous.Fraction() < threshold &&
+			usage.Fraction() >= threshold {
+			policy.OnThreshold(usage, threshold)
+		}
+	}
+}
+
+// refuses or delays a request according to the api limit policy.
+func (client *Client) checkApiUsage(
+	ctx context.Context,
+) error {
+	policy := client.apiLimitPolicy
+	if policy == nil {
+		return nil
+	}
+	usage, ok := client.ApiUsage()
+	if !ok {
+		return nil
+	}
+
+	fraction := usage.Fraction()
+	if policy.RefuseAbove > 0 && fraction >= policy.RefuseAbove {
+		if client.probeApiUsage(policy.MaxUsageAge) {
+			client.logger.InfoContext(
+				ctx,
+				"refreshing stale api usage",
+				"used", usage.Used,
+				"max", usage.Max,
+			)
+			return nil
+		}
+		client.logger.WarnContext(
+			ctx,
+			"refusing request near api limit",
+			"used", usage.Used,
+			"max", usage.Max,
+		)
+		return ErrApiLimit
+	}
+	if policy.ThrottleAbove > 0 && fraction >= policy.ThrottleAbove {
+		client.logger.DebugContext(
+			ctx,
+			"throttling request near api limit",
+			"used", usage.Used,
+			"max", usage.Max,
+		)
+		return sleepContext(ctx, policy.ThrottleDelay)
+	}
+	return nil
+}
+
+// reports whether a refused request should be sent anyway because the usage
+// is older than maxAge, at most once per maxAge. without it a refused client
+// would never see usage drop again.
+func (client *Client) probeApiUsage(
+	maxAge time.Duration,
+) bool {
+	if maxAge <= 0 {
+		maxAge = DefaultMaxUsageAge
+	}
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(client.apiUsage.UpdatedAt) < maxAge ||
+		now.Sub(client.apiUsageProbe) < maxAge {
+		return false
+	}
+	client.apiUsageProbe = now
+	return true
+}
+
+var ErrApiLimit = errors.New("api usage limit reached")
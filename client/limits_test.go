@@ -0,0 +1,180 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stackasaur/goforce/auth"
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+func TestParseLimitInfo(t *testing.T) {
+	usage, ok := parseLimitInfo(
+		"api-usage=18/5000, per-app-api-usage=17/250(appName=sample-app)",
+	)
+	if !ok {
+		t.Fatal("expected usage to be parsed")
+	}
+	if usage.Used != 18 || usage.Max != 5000 || usage.Remaining() != 4982 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+
+	_, ok = parseLimitInfo("per-app-api-usage=17/250(appName=sample-app)")
+	if ok {
+		t.Fatal("expected no usage")
+	}
+}
+
+func TestApiLimitPolicy(t *testing.T) {
+	used := 0
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			used += 40
+			w.Header().Set(
+				"Sforce-Limit-Info",
+				fmt.Sprintf("api-usage=%d/100", used),
+			)
+			w.WriteHeader(http.StatusNoContent)
+		},
+	))
+	defer server.Close()
+
+	var crossed []float64
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow: &auth.SessionFlow{
+				AccessToken: "accessToken",
+				InstanceUrl: server.URL,
+			},
+			ApiLimitPolicy: &ApiLimitPolicy{
+				Thresholds: []float64{0.5, 0.75},
+				OnThreshold: func(_ ApiUsage, threshold float64) {
+					crossed = append(crossed, threshold)
+				},
+				RefuseAbove: 0.8,
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := sfdcClient.ApiUsage(); ok {
+		t.Fatal("expected no usage before the first request")
+	}
+
+	path, _ := url.Parse("/services/data/v60.0/")
+	req := Req.GenericRequest{
+		Method: http.MethodGet,
+		Path:   path,
+	}
+	for range 2 {
+		res, err := sfdcClient.Send(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+
+	usage, ok := sfdcClient.ApiUsage()
+	if !ok || usage.Used != 80 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+	if len(crossed) != 2 || crossed[0] != 0.5 || crossed[1] != 0.75 {
+		t.Fatalf("unexpected thresholds %v", crossed)
+	}
+
+	_, err = sfdcClient.Send(req)
+	if !errors.Is(err, ErrApiLimit) {
+		t.Fatalf("expected ErrApiLimit, received %v", err)
+	}
+}
+
+func TestApiLimitRecovers(t *testing.T) {
+	used := 90
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(
+				"Sforce-Limit-Info",
+				fmt.Sprintf("api-usage=%d/100", used),
+			)
+			w.WriteHeader(http.StatusNoContent)
+		},
+	))
+	defer server.Close()
+
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow: &auth.SessionFlow{
+				AccessToken: "accessToken",
+				InstanceUrl: server.URL,
+			},
+			ApiLimitPolicy: &ApiLimitPolicy{
+				RefuseAbove: 0.8,
+				MaxUsageAge: time.Hour,
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := url.Parse("/services/data/v60.0/")
+	req := Req.GenericRequest{
+		Method: http.MethodGet,
+		Path:   path,
+	}
+	res, err := sfdcClient.Send(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	_, err = sfdcClient.Send(req)
+	if !errors.Is(err, ErrApiLimit) {
+		t.Fatalf("expected %v, actual %v", ErrApiLimit, err)
+	}
+
+	// the daily window moved on, once the usage is stale one request is let
+	// through and its response lifts the refusal
+	used = 10
+	sfdcClient.mu.Lock()
+	sfdcClient.apiUsage.UpdatedAt = time.Now().Add(-2 * time.Hour)
+	sfdcClient.mu.Unlock()
+
+	for range 2 {
+		res, err = sfdcClient.Send(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	usage, _ := sfdcClient.ApiUsage()
+	if usage.Used != 10 {
+		t.Errorf("expected %v, actual %v", 10, usage.Used)
+	}
+}
+
+func TestApiLimitProbesOnce(t *testing.T) {
+	sfdcClient := &Client{
+		apiUsage: ApiUsage{
+			Used:      90,
+			Max:       100,
+			UpdatedAt: time.Now().Add(-time.Hour),
+		},
+	}
+
+	if !sfdcClient.probeApiUsage(time.Minute) {
+		t.Errorf("expected %v, actual %v", true, false)
+	}
+	// still refused until the probe's response arrives
+	if sfdcClient.probeApiUsage(time.Minute) {
+		t.Errorf("expected %v, actual %v", false, true)
+	}
+}
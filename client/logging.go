@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/stackasaur/goforce/auth"
+)
+
+// headers that carry credentials and are never logged.
+var redactedHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// copy of header with credentials replaced by auth.Redacted.
+func redactHeader(
+	header http.Header,
+) http.Header {
+	redacted := header.Clone()
+	for _, name := range redactedHeaders {
+		if len(redacted.Values(name)) > 0 {
+			redacted.Set(name, auth.Redacted)
+		}
+	}
+	return redacted
+}
+
+// the auth flow as logged. the built in flows redact their own secrets through
+// slog.LogValuer, any other flow is logged by type only as its fields may hold
+// credentials.
+func logFlow(
+	authFlow auth.AuthFlow,
+) any {
+	if _, ok := authFlow.(slog.LogValuer); ok {
+		return authFlow
+	}
+	return fmt.Sprintf("%T", authFlow)
+}
+
+// logs the outcome of a single http call. errors and error responses are
+// warnings, except 401 which is expected when a token expires and is handled
+// by refreshing.
+func (client *Client) logResponse(
+	ctx context.Context,
+	httpRequest *http.Request,
+	httpResponse *http.Response,
+	duration time.Duration,
+	err error,
+) {
+	attrs := []slog.Attr{
+		slog.String("method", httpRequest.Method),
+		slog.String("url", httpRequest.URL.Redacted()),
+		slog.Duration("duration", duration),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+		client.logger.LogAttrs(ctx, slog.LevelError, "request failed", attrs...)
+		return
+	}
+
+	attrs = append(attrs, slog.Int("status", httpResponse.StatusCode))
+	level := slog.LevelDebug
+	if httpResponse.StatusCode >= 400 &&
+		httpResponse.StatusCode != http.StatusUnauthorized {
+		level = slog.LevelWarn
+	}
+	client.logger.LogAttrs(ctx, level, "response received", attrs...)
+}
+
+// logger that drops everything, used when none is configured.
+func discardLogger() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stackasaur/goforce/auth"
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+func TestLoggingRedactsCredentials(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/services/oauth2/token" {
+				fmt.Fprintf(
+					w,
+					`{"access_token":"secretAccessToken","instance_url":%q,"issued_at":"1700000000000"}`,
+					server.URL,
+				)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`[{"errorCode":"NOT_FOUND","message":"not found"}]`))
+		},
+	))
+	defer server.Close()
+
+	var buffer bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(
+		&buffer,
+		&slog.HandlerOptions{Level: slog.LevelDebug},
+	))
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow: auth.UsernamePasswordFlow{
+				ClientId:      "clientId",
+				ClientSecret:  "secretClientSecret",
+				Username:      "user@example.com",
+				Password:      "secretPassword",
+				SecurityToken: "secretSecurityToken",
+				TokenEndpoint: server.URL + "/services/oauth2/token",
+			},
+			Logger: logger,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := url.Parse("/services/data/v60.0/sobjects/Account/001000000000001AAA")
+	res, err := sfdcClient.Send(Req.GenericRequest{
+		Method: http.MethodGet,
+		Path:   path,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	output := buffer.String()
+	for _, secret := range []string{
+		"secretAccessToken",
+		"secretClientSecret",
+		"secretPassword",
+		"secretSecurityToken",
+	} {
+		if strings.Contains(output, secret) {
+			t.Errorf("expected %v to be redacted, actual %v", secret, output)
+		}
+	}
+	for _, message := range []string{
+		`"msg":"logging in"`,
+		`"msg":"token refreshed"`,
+		`"msg":"sending request"`,
+		`"level":"WARN","msg":"response received"`,
+		`"status":404`,
+	} {
+		if !strings.Contains(output, message) {
+			t.Errorf("expected %v, actual %v", message, output)
+		}
+	}
+}
+
+func TestRedactHeader(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer secret")
+	header.Set("Content-Type", "application/json")
+
+	redacted := redactHeader(header)
+	if redacted.Get("Authorization") != auth.Redacted {
+		t.Errorf("expected %v, actual %v", auth.Redacted, redacted.Get("Authorization"))
+	}
+	if redacted.Get("Content-Type") != "application/json" {
+		t.Errorf("expected %v, actual %v", "application/json", redacted.Get("Content-Type"))
+	}
+	if header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("expected %v, actual %v", "Bearer secret", header.Get("Authorization"))
+	}
+}
+
+// a custom flow without a LogValue method.
+type secretFlow struct {
+	countingFlow
+	Secret string
+}
+
+func TestLoggingCustomFlow(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(
+		&buffer,
+		&slog.HandlerOptions{Level: slog.LevelDebug},
+	))
+	_, err := NewClient(
+		ClientConfig{
+			AuthFlow: &secretFlow{
+				countingFlow: countingFlow{
+					instanceUrl: "https://example.my.salesforce.com",
+				},
+				Secret: "secretValue",
+			},
+			Logger: logger,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := buffer.String()
+	if strings.Contains(output, "secretValue") {
+		t.Errorf("expected %v to be redacted, actual %v", "secretValue", output)
+	}
+	if !strings.Contains(output, `"flow":"*client.secretFlow"`) {
+		t.Errorf("expected %v, actual %v", `"flow":"*client.secretFlow"`, output)
+	}
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"net/http"
+
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+// RoundTrip sends a built request. req is the request it was built from and
+// httpRequest already carries the context, authorization and version.
+type RoundTrip func(
+	req Req.SfdcRequest,
+	httpRequest *http.Request,
+) (*http.Response, error)
+
+// Middleware wraps every http call made by Client.Send, including resends
+// after a 401 and retries. it can inspect or modify the request, replace or
+// observe the response, or skip calling next entirely. middlewares run inside
+// the rate limiter and concurrency cap, so they only measure the call itself.
+type Middleware func(next RoundTrip) RoundTrip
+
+// composes middlewares around the http client, the first one is outermost.
+func chain(
+	httpClient *http.Client,
+	middlewares []Middleware,
+) RoundTrip {
+	var ret RoundTrip = func(
+		_ Req.SfdcRequest,
+		httpRequest *http.Request,
+	) (*http.Response, error) {
+		return httpClient.Do(httpRequest)
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		ret = middlewares[i](ret)
+	}
+	return ret
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stackasaur/goforce/auth"
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+func TestMiddlewares(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Test") != "outer,inner" {
+				t.Errorf("unexpected header %q", r.Header.Get("X-Test"))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		},
+	))
+	defer server.Close()
+
+	var order []string
+	tagging := func(name string) Middleware {
+		return func(next RoundTrip) RoundTrip {
+			return func(
+				req Req.SfdcRequest,
+				httpRequest *http.Request,
+			) (*http.Response, error) {
+				order = append(order, name)
+				tags := httpRequest.Header.Get("X-Test")
+				if len(tags) > 0 {
+					tags += ","
+				}
+				httpRequest.Header.Set("X-Test", tags+name)
+				return next(req, httpRequest)
+			}
+		}
+	}
+
+	// fails the first call without reaching the server
+	chaosCalls := 0
+	chaos := func(next RoundTrip) RoundTrip {
+		return func(
+			req Req.SfdcRequest,
+			httpRequest *http.Request,
+		) (*http.Response, error) {
+			chaosCalls++
+			if chaosCalls == 1 {
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Header:     http.Header{},
+					Body:       io.NopCloser(strings.NewReader("")),
+					Request:    httpRequest,
+				}, nil
+			}
+			return next(req, httpRequest)
+		}
+	}
+
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow: &auth.SessionFlow{
+				AccessToken: "accessToken",
+				InstanceUrl: server.URL,
+			},
+			RetryPolicy: testRetryPolicy(),
+			Middlewares: []Middleware{
+				chaos,
+				tagging("outer"),
+				tagging("inner"),
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := url.Parse("/services/data/v60.0/")
+	res, err := sfdcClient.Send(Req.GenericRequest{
+		Method: http.MethodGet,
+		Path:   path,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected status %d", res.StatusCode)
+	}
+	if chaosCalls != 2 {
+		t.Fatalf("expected the injected failure to be retried, received %d calls", chaosCalls)
+	}
+	if strings.Join(order, ",") != "outer,inner" {
+		t.Fatalf("unexpected order %v", order)
+	}
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+// token bucket limiting the rate of requests sent by a client.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(
+	rate float64,
+	burst int,
+) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// blocks until a request may be sent or ctx is done.
+func (limiter *rateLimiter) wait(
+	ctx context.Context,
+) error {
+	for {
+		limiter.mu.Lock()
+		now := time.Now()
+		limiter.tokens = min(
+			limiter.burst,
+			limiter.tokens+now.Sub(limiter.last).Seconds()*limiter.rate,
+		)
+		limiter.last = now
+		if limiter.tokens >= 1 {
+			limiter.tokens--
+			limiter.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration(
+			(1 - limiter.tokens) / limiter.rate * float64(time.Second),
+		)
+		limiter.mu.Unlock()
+
+		err := sleepContext(ctx, delay)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// a request holds a slot from before it is sent until its response body is
+// closed, since salesforce counts a request as running until then.
+type slotBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (body *slotBody) Close() error {
+	err := body.ReadCloser.Close()
+	body.once.Do(body.release)
+	return err
+}
+
+// sends a built request through the middleware chain, waiting for the rate
+// limiter and a concurrency slot first, and records the api usage of the
+// response.
+func (client *Client) do(
+	ctx context.Context,
+	req Req.SfdcRequest,
+	httpRequest *http.Request,
+) (*http.Response, error) {
+	if client.rateLimiter != nil {
+		err := client.rateLimiter.wait(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	release := func() {}
+	if client.slots != nil {
+		select {
+		case client.slots <- struct{}{}:
+			release = func() { <-client.slots }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	client.logger.LogAttrs(
+		ctx,
+		slog.LevelDebug,
+		"sending request",
+		slog.String("method", httpRequest.Method),
+		slog.String("url", httpRequest.URL.Redacted()),
+		slog.Any("header", redactHeader(httpRequest.Header)),
+	)
+	start := time.Now()
+	httpResponse, err := client.roundTrip(req, httpRequest)
+	client.logResponse(ctx, httpRequest, httpResponse, time.Since(start), err)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	if client.gzipResponses {
+		err = decompressResponse(httpResponse)
+		if err != nil {
+			release()
+			return nil, err
+		}
+	}
+	client.trackApiUsage(httpResponse)
+
+	httpResponse.Body = &slotBody{
+		ReadCloser: httpResponse.Body,
+		release:    release,
+	}
+	return httpResponse, nil
+}
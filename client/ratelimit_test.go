@@ -0,0 +1,183 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stackasaur/goforce/auth"
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				previous := maxInFlight.Load()
+				if current <= previous ||
+					maxInFlight.CompareAndSwap(previous, current) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			w.WriteHeader(http.StatusNoContent)
+		},
+	))
+	defer server.Close()
+
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow: &auth.SessionFlow{
+				AccessToken: "accessToken",
+				InstanceUrl: server.URL,
+			},
+			MaxConcurrentRequests: 2,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := url.Parse("/services/data/v60.0/")
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := sfdcClient.Send(Req.GenericRequest{
+				Method: http.MethodGet,
+				Path:   path,
+			})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight.Load() > 2 {
+		t.Fatalf("expected at most 2 requests in flight, received %d", maxInFlight.Load())
+	}
+
+	// with both slots held the next request waits until its context is done
+	var held []*http.Response
+	for range 2 {
+		res, err := sfdcClient.Send(Req.GenericRequest{
+			Method: http.MethodGet,
+			Path:   path,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		held = append(held, res)
+	}
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		20*time.Millisecond,
+	)
+	defer cancel()
+	_, err = sfdcClient.SendContext(
+		ctx,
+		Req.GenericRequest{
+			Method: http.MethodGet,
+			Path:   path,
+		},
+	)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, received %v", err)
+	}
+	for _, res := range held {
+		res.Body.Close()
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(50, 1)
+
+	start := time.Now()
+	for range 5 {
+		err := limiter.wait(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	elapsed := time.Since(start)
+
+	// the first request uses the burst, the other four wait 20ms each
+	if elapsed < 70*time.Millisecond {
+		t.Fatalf("expected requests to be limited, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	limiter = newRateLimiter(0.001, 1)
+	limiter.wait(ctx)
+	err := limiter.wait(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, received %v", err)
+	}
+}
+
+// inspecting an error response for retryable error codes must neither release
+// the concurrency slot early nor truncate the body handed to the caller.
+func TestInspectedErrorHoldsSlot(t *testing.T) {
+	body := `[{"errorCode":"INVALID_FIELD","message":"` +
+		strings.Repeat("a", maxInspectedErrorBody) + `"}]`
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(body))
+		},
+	))
+	defer server.Close()
+
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow: &auth.SessionFlow{
+				AccessToken: "accessToken",
+				InstanceUrl: server.URL,
+			},
+			RetryPolicy:           testRetryPolicy(),
+			MaxConcurrentRequests: 1,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := url.Parse("/services/data/v60.0/sobjects/Account")
+	res, err := sfdcClient.Send(Req.GenericRequest{
+		Method: http.MethodPost,
+		Path:   path,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sfdcClient.slots) != 1 {
+		t.Errorf("expected %v, actual %v", 1, len(sfdcClient.slots))
+	}
+	actual, _ := io.ReadAll(res.Body)
+	if len(actual) != len(body) {
+		t.Errorf("expected %v, actual %v", len(body), len(actual))
+	}
+	res.Body.Close()
+	if len(sfdcClient.slots) != 0 {
+		t.Errorf("expected %v, actual %v", 0, len(sfdcClient.slots))
+	}
+}
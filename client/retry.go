@@ -0,0 +1,195 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+// RetryPolicy controls how Send retries transient failures. requests using an
+// idempotent method are retried on network errors, RetryableStatusCodes and
+// RetryableErrorCodes. other methods (POST, PATCH) are only retried on
+// RetryableErrorCodes, since an error body means salesforce rejected the
+// request without applying it, unless RetryNonIdempotent is set.
+type RetryPolicy struct {
+	// total attempts including the first, values below 2 disable retries.
+	MaxAttempts int
+	// backoff before the first retry, doubled (by Multiplier) for each
+	// subsequent one up to MaxBackoff. a Retry-After header takes precedence
+	// but is capped at MaxBackoff as well.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// fraction of the backoff randomized to spread out retries, 0 to 1.
+	Jitter               float64
+	RetryableStatusCodes []int
+	// ApiError.ErrorCode values that are retried.
+	RetryableErrorCodes []string
+	RetryNonIdempotent  bool
+}
+
+// a policy suited to most integrations: 4 attempts starting at half a second.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RetryableErrorCodes: []string{
+			"UNABLE_TO_LOCK_ROW",
+			"REQUEST_LIMIT_EXCEEDED",
+			"SERVER_UNAVAILABLE",
+		},
+	}
+}
+
+// only the start of an error body is inspected for error codes.
+const maxInspectedErrorBody = 1 << 20
+
+// decodes the api errors of a response without consuming its body, the part
+// read is put back in front of the remainder. nil when the body is not a json
+// error list. the original body is kept as the closer so the concurrency slot
+// it holds is only released once the caller closes the response, and bodies
+// over maxInspectedErrorBody are still returned whole.
+func peekApiErrors(
+	httpResponse *http.Response,
+) []Req.ApiError {
+	original := httpResponse.Body
+	peeked, err := io.ReadAll(
+		io.LimitReader(original, maxInspectedErrorBody),
+	)
+	httpResponse.Body = struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.MultiReader(bytes.NewReader(peeked), original),
+		Closer: original,
+	}
+	if err != nil {
+		return nil
+	}
+
+	var apiErrors []Req.ApiError
+	if json.Unmarshal(peeked, &apiErrors) != nil {
+		return nil
+	}
+	return apiErrors
+}
+
+func isIdempotent(
+	method string,
+) bool {
+	switch method {
+	case http.MethodGet,
+		http.MethodHead,
+		http.MethodOptions,
+		http.MethodPut,
+		http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// reports whether a completed attempt should be retried. the response body
+// can still be read by the caller after being inspected.
+func (policy *RetryPolicy) shouldRetry(
+	ctx context.Context,
+	method string,
+	httpResponse *http.Response,
+	err error,
+) bool {
+	idempotent := isIdempotent(method) || policy.RetryNonIdempotent
+
+	if err != nil {
+		if ctx.Err() != nil || errors.Is(err, ErrToken) ||
+			errors.Is(err, ErrClosed) {
+			return false
+		}
+		return idempotent
+	}
+	if httpResponse.StatusCode < 400 {
+		return false
+	}
+	if idempotent &&
+		slices.Contains(policy.RetryableStatusCodes, httpResponse.StatusCode) {
+		return true
+	}
+	if len(policy.RetryableErrorCodes) == 0 {
+		return false
+	}
+
+	apiErrors := peekApiErrors(httpResponse)
+	for _, apiError := range apiErrors {
+		if slices.Contains(policy.RetryableErrorCodes, apiError.ErrorCode) {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff before the given retry (1 for the first), honoring Retry-After up to
+// MaxBackoff.
+func (policy *RetryPolicy) backoff(
+	retry int,
+	httpResponse *http.Response,
+) time.Duration {
+	if httpResponse != nil {
+		seconds, err := strconv.Atoi(httpResponse.Header.Get("Retry-After"))
+		if err == nil && seconds >= 0 {
+			retryAfter := time.Duration(seconds) * time.Second
+			if policy.MaxBackoff > 0 {
+				retryAfter = min(retryAfter, policy.MaxBackoff)
+			}
+			return retryAfter
+		}
+	}
+
+	multiplier := policy.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	backoff := float64(policy.InitialBackoff)
+	for range retry - 1 {
+		backoff *= multiplier
+	}
+	if policy.MaxBackoff > 0 && backoff > float64(policy.MaxBackoff) {
+		backoff = float64(policy.MaxBackoff)
+	}
+	if policy.Jitter > 0 {
+		jitter := min(policy.Jitter, 1)
+		backoff -= backoff * jitter * rand.Float64()
+	}
+	return time.Duration(backoff)
+}
+
+// waits for d or until ctx is done.
+func sleepContext(
+	ctx context.Context,
+	d time.Duration,
+) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
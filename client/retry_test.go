@@ -0,0 +1,200 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stackasaur/goforce/auth"
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+func testRetryPolicy() *RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = 5 * time.Millisecond
+	return policy
+}
+
+func TestRetry(t *testing.T) {
+	var attempts int
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+
+			switch r.URL.Path {
+			case "/unavailable":
+				if attempts < 3 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			case "/locked":
+				if attempts < 2 {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`[{"errorCode":"UNABLE_TO_LOCK_ROW","message":"unable to obtain exclusive access to this record"}]`))
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+			case "/malformed":
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`[{"errorCode":"MALFORMED_QUERY","message":"unexpected token"}]`))
+			}
+		},
+	))
+	defer server.Close()
+
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow: &auth.SessionFlow{
+				AccessToken: "accessToken",
+				InstanceUrl: server.URL,
+			},
+			RetryPolicy: testRetryPolicy(),
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name             string
+		method           string
+		path             string
+		expectedStatus   int
+		expectedAttempts int
+	}{
+		{"idempotent status", http.MethodGet, "/unavailable", 200, 3},
+		{"non-idempotent status", http.MethodPost, "/unavailable", 503, 1},
+		{"non-idempotent error code", http.MethodPost, "/locked", 201, 2},
+		{"non-retryable error code", http.MethodGet, "/malformed", 400, 1},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name,
+			func(t *testing.T) {
+				attempts = 0
+				bodies = nil
+				path, _ := url.Parse(test.path)
+
+				res, err := sfdcClient.Send(Req.GenericRequest{
+					Method: test.method,
+					Path:   path,
+					Body:   []byte(`{"Name":"test"}`),
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer res.Body.Close()
+
+				if res.StatusCode != test.expectedStatus {
+					t.Fatalf(
+						"expected %v, actual %v",
+						test.expectedStatus,
+						res.StatusCode,
+					)
+				}
+				if attempts != test.expectedAttempts {
+					t.Fatalf(
+						"expected %v attempts, actual %v",
+						test.expectedAttempts,
+						attempts,
+					)
+				}
+				for _, body := range bodies {
+					if body != `{"Name":"test"}` {
+						t.Fatalf("unexpected request body %q", body)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestRetryKeepsErrorBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`[{"errorCode":"UNABLE_TO_LOCK_ROW","message":"locked"}]`))
+		},
+	))
+	defer server.Close()
+
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow: &auth.SessionFlow{
+				AccessToken: "accessToken",
+				InstanceUrl: server.URL,
+			},
+			RetryPolicy: testRetryPolicy(),
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := url.Parse("/")
+	res, err := sfdcClient.Send(Req.GenericRequest{
+		Method: http.MethodGet,
+		Path:   path,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(res.Body)
+	expected := `[{"errorCode":"UNABLE_TO_LOCK_ROW","message":"locked"}]`
+	if string(body) != expected {
+		t.Fatalf(
+			"expected %v, actual %v",
+			expected,
+			string(body),
+		)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     300 * time.Millisecond,
+		Multiplier:     2,
+	}
+
+	expected := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		300 * time.Millisecond,
+	}
+	for i, backoff := range expected {
+		actual := policy.backoff(i+1, nil)
+		if backoff != actual {
+			t.Fatalf(
+				"expected %v, actual %v",
+				backoff,
+				actual,
+			)
+		}
+	}
+
+	res := &http.Response{
+		Header: http.Header{
+			"Retry-After": []string{"2"},
+		},
+	}
+	if policy.backoff(1, res) != 300*time.Millisecond {
+		t.Errorf("expected %v, actual %v", 300*time.Millisecond, policy.backoff(1, res))
+	}
+	policy.MaxBackoff = 5 * time.Second
+	if policy.backoff(1, res) != 2*time.Second {
+		t.Errorf("expected %v, actual %v", 2*time.Second, policy.backoff(1, res))
+	}
+}
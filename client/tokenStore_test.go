@@ -0,0 +1,190 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stackasaur/goforce/auth"
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+// auth flow handing out sequential tokens for the given instance.
+type countingFlow struct {
+	instanceUrl string
+	calls       int
+}
+
+func (flow *countingFlow) NewToken(
+	_ *http.Client,
+) (auth.Token, error) {
+	flow.calls++
+	return auth.Token{
+		AccessToken: fmt.Sprintf("token%d", flow.calls),
+		InstanceUrl: flow.instanceUrl,
+		Expiration:  time.Now().Add(time.Hour),
+	}, nil
+}
+func (flow *countingFlow) RefreshToken(
+	httpClient *http.Client,
+) (auth.Token, error) {
+	return flow.NewToken(httpClient)
+}
+
+func TestClientTokenStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "Bearer token1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		},
+	))
+	defer server.Close()
+
+	store := auth.NewMemoryTokenStore()
+	key := auth.TokenKey{
+		OrgId:    "00D000000000001",
+		Username: "user@example.com",
+	}
+	authFlow := &countingFlow{
+		instanceUrl: server.URL,
+	}
+
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow:   authFlow,
+			TokenStore: store,
+			TokenKey:   key,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a second client reuses the stored token instead of logging in
+	_, err = NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow:   authFlow,
+			TokenStore: store,
+			TokenKey:   key,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authFlow.calls != 1 {
+		t.Fatalf("expected 1 login, received %d", authFlow.calls)
+	}
+
+	path, _ := url.Parse("/services/data/v60.0/")
+	res, err := sfdcClient.Send(Req.GenericRequest{
+		Method: http.MethodGet,
+		Path:   path,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected status %d", res.StatusCode)
+	}
+
+	stored, ok, err := store.Load(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || stored.AccessToken != "token2" {
+		t.Fatalf("expected refreshed token to be stored, received %v", stored)
+	}
+}
+
+func TestClientTokenStoreRequiresKey(t *testing.T) {
+	_, err := NewClient(
+		ClientConfig{
+			AuthFlow:   &countingFlow{},
+			TokenStore: auth.NewMemoryTokenStore(),
+		},
+	)
+	if err != ErrTokenKey {
+		t.Fatalf("expected ErrTokenKey, received %v", err)
+	}
+}
+
+func TestClientStoredRefreshToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/services/oauth2/token" {
+				r.ParseForm()
+				if r.Form.Get("refresh_token") != "refreshToken" {
+					t.Errorf("expected %v, actual %v", "refreshToken", r.Form.Get("refresh_token"))
+				}
+				fmt.Fprintf(
+					w,
+					`{"access_token":"refreshed","instance_url":%q,"issued_at":"%d"}`,
+					"http://"+r.Host,
+					time.Now().UnixMilli(),
+				)
+				return
+			}
+			if r.Header.Get("Authorization") != "Bearer refreshed" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		},
+	))
+	defer server.Close()
+
+	// logged in by another process
+	store := auth.NewMemoryTokenStore()
+	key := auth.TokenKey{
+		OrgId:    "00D000000000001",
+		Username: "user@example.com",
+	}
+	store.Save(key, auth.Token{
+		AccessToken:  "stored",
+		InstanceUrl:  server.URL,
+		Expiration:   time.Now().Add(time.Hour),
+		RefreshToken: "refreshToken",
+	})
+
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow: &auth.WebServerFlow{
+				ClientId:      "clientId",
+				TokenEndpoint: server.URL + "/services/oauth2/token",
+			},
+			TokenStore: store,
+			TokenKey:   key,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := url.Parse("/services/data/v60.0/")
+	res, err := sfdcClient.Send(Req.GenericRequest{
+		Method: http.MethodGet,
+		Path:   path,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		t.Errorf("expected %v, actual %v", http.StatusNoContent, res.StatusCode)
+	}
+
+	stored, _, _ := store.Load(key)
+	if stored.RefreshToken != "refreshToken" {
+		t.Errorf("expected %v, actual %v", "refreshToken", stored.RefreshToken)
+	}
+}
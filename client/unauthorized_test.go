@@ -0,0 +1,82 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stackasaur/goforce/auth"
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+func TestUnauthorizedResendsBody(t *testing.T) {
+	for _, method := range []string{http.MethodPost, http.MethodPatch} {
+		t.Run(
+			method,
+			func(t *testing.T) {
+				var bodies []string
+				server := httptest.NewServer(http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						body, _ := io.ReadAll(r.Body)
+						bodies = append(bodies, string(body))
+
+						if r.Header.Get("Authorization") != "Bearer fresh" {
+							w.WriteHeader(http.StatusUnauthorized)
+							w.Write([]byte(`[{"errorCode":"INVALID_SESSION_ID","message":"Session expired or invalid"}]`))
+							return
+						}
+						w.WriteHeader(http.StatusCreated)
+					},
+				))
+				defer server.Close()
+
+				sfdcClient, err := NewClient(
+					ClientConfig{
+						HttpClient: server.Client(),
+						AuthFlow: &auth.SessionFlow{
+							AccessToken: "expired",
+							InstanceUrl: server.URL,
+							OnRefresh: func(_ *http.Client) (auth.Token, error) {
+								return auth.Token{
+									AccessToken: "fresh",
+									InstanceUrl: server.URL,
+								}, nil
+							},
+						},
+					},
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				path, _ := url.Parse("/services/data/v60.0/sobjects/Account/")
+				res, err := sfdcClient.Send(Req.GenericRequest{
+					Method: method,
+					Path:   path,
+					Headers: map[string]string{
+						"Content-Type": "application/json",
+					},
+					Body: []byte(`{"Name":"test"}`),
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				res.Body.Close()
+
+				if res.StatusCode != http.StatusCreated {
+					t.Fatalf("unexpected status %d", res.StatusCode)
+				}
+				if len(bodies) != 2 {
+					t.Fatalf("expected 2 requests, received %d", len(bodies))
+				}
+				for _, body := range bodies {
+					if body != `{"Name":"test"}` {
+						t.Fatalf("unexpected request body %q", body)
+					}
+				}
+			},
+		)
+	}
+}
@@ -1,6 +1,15 @@
 package client
 
-import "testing"
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stackasaur/goforce/auth"
+	Req "github.com/stackasaur/goforce/shared/request"
+)
 
 func TestValidateVersion(t *testing.T) {
 	validVersion := MaxSupportedVersion - 1
@@ -43,3 +52,163 @@ func TestToVersionString(t *testing.T) {
 		)
 	}
 }
+
+func newVersionsServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/services/data" {
+				t.Errorf("unexpected path %s", r.URL.Path)
+			}
+			w.Write([]byte(`[
+				{"label":"Summer '25","url":"/services/data/v64.0","version":"64.0"},
+				{"label":"Spring '26","url":"/services/data/v66.0","version":"66.0"},
+				{"label":"Winter '26","url":"/services/data/v65.0","version":"65.0"}
+			]`))
+		},
+	))
+}
+
+func TestDiscoverVersions(t *testing.T) {
+	server := newVersionsServer(t)
+	defer server.Close()
+
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow: &auth.SessionFlow{
+				AccessToken: "accessToken",
+				InstanceUrl: server.URL,
+			},
+			DiscoverVersions: true,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "66.0"
+	actual := sfdcClient.GetVersion()
+	if expected != actual {
+		t.Fatalf("expected %v, actual %v", expected, actual)
+	}
+
+	// newer than MaxSupportedVersion but supported by the org
+	err = sfdcClient.SetVersion(66)
+	if err != nil {
+		t.Errorf("expected %v, actual %v", nil, err)
+	}
+	// within the constants but not supported by the org
+	err = sfdcClient.SetVersion(60)
+	if !errors.Is(err, ErrVersion) {
+		t.Errorf("expected %v, actual %v", ErrVersion, err)
+	}
+
+	versions, err := sfdcClient.AvailableVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 3 || versions[0].Label != "Summer '25" {
+		t.Errorf("unexpected versions %+v", versions)
+	}
+}
+
+func TestDiscoverVersionsUnsupported(t *testing.T) {
+	server := newVersionsServer(t)
+	defer server.Close()
+
+	store := auth.NewMemoryTokenStore()
+	key := auth.TokenKey{
+		OrgId:    "00D000000000001",
+		Username: "user@example.com",
+	}
+	authFlow := &revokingFlow{
+		countingFlow: countingFlow{
+			instanceUrl: server.URL,
+		},
+	}
+	_, err := NewClient(
+		ClientConfig{
+			HttpClient:       server.Client(),
+			AuthFlow:         authFlow,
+			TokenStore:       store,
+			TokenKey:         key,
+			Version:          60,
+			DiscoverVersions: true,
+		},
+	)
+	if !errors.Is(err, ErrVersion) {
+		t.Fatalf("expected %v, actual %v", ErrVersion, err)
+	}
+
+	// the token logged in with is not left behind
+	if len(authFlow.revoked) != 1 || authFlow.revoked[0] != "token1" {
+		t.Errorf("expected %v, actual %v", []string{"token1"}, authFlow.revoked)
+	}
+	if _, ok, _ := store.Load(key); ok {
+		t.Errorf("expected %v, actual %v", false, ok)
+	}
+}
+
+type versionedRequest struct {
+	Req.GenericRequest
+	version string
+}
+
+func (req versionedRequest) GetVersion() string {
+	return req.version
+}
+
+func TestRequestVersionOverride(t *testing.T) {
+	sent := 0
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			sent++
+		},
+	))
+	defer server.Close()
+
+	sfdcClient, err := NewClient(
+		ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow: &auth.SessionFlow{
+				AccessToken: "accessToken",
+				InstanceUrl: server.URL,
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := url.Parse("/services/data")
+	tests := []struct {
+		version string
+		err     error
+	}{
+		{"", nil},
+		{"60.0", nil},
+		{"60", nil},
+		{toVersionString(MaxSupportedVersion + 1), ErrVersion},
+		{"sixty", ErrVersion},
+	}
+	for _, test := range tests {
+		res, err := sfdcClient.Send(versionedRequest{
+			GenericRequest: Req.GenericRequest{
+				Method: http.MethodGet,
+				Path:   path,
+			},
+			version: test.version,
+		})
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: expected %v, actual %v", test.version, test.err, err)
+		}
+		if res != nil {
+			res.Body.Close()
+		}
+	}
+
+	expected := 3
+	if sent != expected {
+		t.Errorf("expected %v, actual %v", expected, sent)
+	}
+}
@@ -1,7 +1,18 @@
 package client
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
 
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+// bounds used to validate versions when the org's versions have not been
+// discovered, see ClientConfig.DiscoverVersions.
 const MaxSupportedVersion int = 65
 const MinSupportedVersion int = 40
 
@@ -18,3 +29,134 @@ func toVersionString(version int) string {
 		version,
 	)
 }
+
+// an api version supported by the org, as listed by /services/data.
+type ApiVersion struct {
+	Label   string `json:"label"`
+	Url     string `json:"url"`
+	Version string `json:"version"`
+}
+
+// lists the api versions the org supports, oldest first. the result replaces
+// the versions used to validate SetVersion.
+func (client *Client) AvailableVersions() ([]ApiVersion, error) {
+	return client.AvailableVersionsContext(
+		client.GetContext(),
+	)
+}
+
+func (client *Client) AvailableVersionsContext(
+	ctx context.Context,
+) ([]ApiVersion, error) {
+	path, err := url.Parse("/services/data")
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := Req.Do[[]ApiVersion](
+		ctx,
+		client,
+		Req.GenericRequest{
+			Method: http.MethodGet,
+			Path:   path,
+			Headers: map[string]string{
+				"Accept": "application/json",
+			},
+		},
+		http.StatusOK,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]int, 0, len(*res.Body))
+	for _, apiVersion := range *res.Body {
+		version, err := Req.ParseVersion(apiVersion.Version)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	slices.Sort(versions)
+
+	client.mu.Lock()
+	client.versions = versions
+	client.mu.Unlock()
+
+	return *res.Body, nil
+}
+
+// checks the version override of req, if any, is supported.
+func (client *Client) checkRequestVersion(
+	req Req.SfdcRequest,
+) error {
+	versioned, ok := req.(Req.VersionedRequest)
+	if !ok || len(versioned.GetVersion()) == 0 {
+		return nil
+	}
+	version, err := Req.ParseVersion(versioned.GetVersion())
+	if err != nil {
+		return errors.Join(
+			ErrVersion,
+			err,
+		)
+	}
+	if !client.supportsVersion(version) {
+		return errors.Join(
+			ErrVersion,
+			fmt.Errorf("version %d is not supported", version),
+		)
+	}
+	return nil
+}
+
+// checks version against the org's versions once discovered, otherwise
+// against MinSupportedVersion and MaxSupportedVersion.
+func (client *Client) supportsVersion(
+	version int,
+) bool {
+	client.mu.RLock()
+	versions := client.versions
+	client.mu.RUnlock()
+
+	if versions == nil {
+		return validateVersion(version)
+	}
+	return slices.Contains(versions, version)
+}
+
+// discovers the org's versions, selecting the latest when version is zero or
+// checking the requested one is supported.
+func (client *Client) discoverVersion(
+	ctx context.Context,
+	version int,
+) error {
+	_, err := client.AvailableVersionsContext(ctx)
+	if err != nil {
+		return errors.Join(
+			ErrVersion,
+			err,
+		)
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if len(client.versions) == 0 {
+		return errors.Join(
+			ErrVersion,
+			errors.New("org reported no api versions"),
+		)
+	}
+	if version == 0 {
+		version = client.versions[len(client.versions)-1]
+	}
+	if !slices.Contains(client.versions, version) {
+		return errors.Join(
+			ErrVersion,
+			fmt.Errorf("version %d is not supported by the org", version),
+		)
+	}
+	client.version = toVersionString(version)
+	return nil
+}
@@ -0,0 +1,174 @@
+// Package otel instruments a goforce client with opentelemetry. it is a
+// separate module so the client does not depend on opentelemetry.
+package otel
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	globalotel "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/stackasaur/goforce/client"
+)
+
+const instrumentationName = "github.com/stackasaur/goforce/otel"
+
+type Config struct {
+	// default to the global providers when nil.
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+}
+
+// Instrumentation implements client.Instrumentation. every request becomes a
+// client span named after its method and path template, request durations are
+// recorded in a histogram and the org's api usage is exposed as gauges.
+type Instrumentation struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+
+	mu       sync.Mutex
+	apiUsage client.ApiUsage
+}
+
+func NewInstrumentation(
+	config Config,
+) (*Instrumentation, error) {
+	tracerProvider := config.TracerProvider
+	if tracerProvider == nil {
+		tracerProvider = globalotel.GetTracerProvider()
+	}
+	meterProvider := config.MeterProvider
+	if meterProvider == nil {
+		meterProvider = globalotel.GetMeterProvider()
+	}
+
+	instrumentation := Instrumentation{
+		tracer: tracerProvider.Tracer(instrumentationName),
+	}
+	meter := meterProvider.Meter(instrumentationName)
+
+	var err error
+	instrumentation.duration, err = meter.Float64Histogram(
+		"goforce.client.request.duration",
+		metric.WithDescription("duration of salesforce requests including retries"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	used, err := meter.Int64ObservableGauge(
+		"goforce.client.api_usage.used",
+		metric.WithDescription("daily api requests used by the org"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := meter.Int64ObservableGauge(
+		"goforce.client.api_usage.limit",
+		metric.WithDescription("daily api request limit of the org"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	_, err = meter.RegisterCallback(
+		func(_ context.Context, observer metric.Observer) error {
+			instrumentation.mu.Lock()
+			usage := instrumentation.apiUsage
+			instrumentation.mu.Unlock()
+
+			if usage.UpdatedAt.IsZero() {
+				return nil
+			}
+			observer.ObserveInt64(used, int64(usage.Used))
+			observer.ObserveInt64(limit, int64(usage.Max))
+			return nil
+		},
+		used,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &instrumentation, nil
+}
+
+func (instrumentation *Instrumentation) StartRequest(
+	ctx context.Context,
+	info client.RequestInfo,
+) (context.Context, func(client.RequestResult)) {
+	attributes := []attribute.KeyValue{
+		attribute.String("http.request.method", info.Method),
+		attribute.String("url.template", info.PathTemplate),
+	}
+	if len(info.SObject) > 0 {
+		attributes = append(
+			attributes,
+			attribute.String("salesforce.sobject", info.SObject),
+		)
+	}
+
+	ctx, span := instrumentation.tracer.Start(
+		ctx,
+		fmt.Sprintf("%s %s", info.Method, info.PathTemplate),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...),
+	)
+
+	return ctx, func(result client.RequestResult) {
+		defer span.End()
+
+		resultAttributes := []attribute.KeyValue{
+			attribute.Int("salesforce.retry_count", max(result.Attempts-1, 0)),
+		}
+		if result.StatusCode != 0 {
+			resultAttributes = append(
+				resultAttributes,
+				attribute.Int("http.response.status_code", result.StatusCode),
+			)
+		}
+		if len(result.ErrorCode) > 0 {
+			resultAttributes = append(
+				resultAttributes,
+				attribute.String("salesforce.error_code", result.ErrorCode),
+			)
+		}
+		span.SetAttributes(resultAttributes...)
+
+		switch {
+		case result.Err != nil:
+			span.RecordError(result.Err)
+			span.SetStatus(codes.Error, result.Err.Error())
+		case result.StatusCode >= 400:
+			span.SetStatus(codes.Error, result.ErrorCode)
+		}
+
+		metricAttributes := slices.Clone(attributes)
+		if result.StatusCode != 0 {
+			metricAttributes = append(
+				metricAttributes,
+				attribute.Int("http.response.status_code", result.StatusCode),
+			)
+		}
+		instrumentation.duration.Record(
+			ctx,
+			result.Duration.Seconds(),
+			metric.WithAttributes(metricAttributes...),
+		)
+
+		if !result.ApiUsage.UpdatedAt.IsZero() {
+			instrumentation.mu.Lock()
+			instrumentation.apiUsage = result.ApiUsage
+			instrumentation.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package otel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/stackasaur/goforce/auth"
+	"github.com/stackasaur/goforce/client"
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+func TestInstrumentation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Sforce-Limit-Info", "api-usage=25/15000")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`[{"errorCode":"NOT_FOUND","message":"The requested resource does not exist"}]`))
+		},
+	))
+	defer server.Close()
+
+	spanRecorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(spanRecorder),
+	)
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+	)
+
+	instrumentation, err := NewInstrumentation(
+		Config{
+			TracerProvider: tracerProvider,
+			MeterProvider:  meterProvider,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sfdcClient, err := client.NewClient(
+		client.ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow: &auth.SessionFlow{
+				AccessToken: "accessToken",
+				InstanceUrl: server.URL,
+			},
+			Instrumentation: instrumentation,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := url.Parse("/services/data/v60.0/sobjects/Account/001000000000001")
+	res, err := sfdcClient.Send(Req.GenericRequest{
+		Method: http.MethodGet,
+		Path:   path,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	spans := spanRecorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, received %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /services/data/v{version}/sobjects/Account/{id}" {
+		t.Fatalf("unexpected span name %s", span.Name())
+	}
+	if span.Status().Code != codes.Error {
+		t.Fatalf("unexpected span status %v", span.Status())
+	}
+	expected := map[attribute.Key]attribute.Value{
+		"salesforce.sobject":        attribute.StringValue("Account"),
+		"salesforce.error_code":     attribute.StringValue("NOT_FOUND"),
+		"http.response.status_code": attribute.IntValue(404),
+		"salesforce.retry_count":    attribute.IntValue(0),
+	}
+	actual := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		actual[kv.Key] = kv.Value
+	}
+	for key, value := range expected {
+		if actual[key] != value {
+			t.Fatalf(
+				"expected %v=%v, actual %v",
+				key,
+				value.Emit(),
+				actual[key].Emit(),
+			)
+		}
+	}
+
+	var metrics metricdata.ResourceMetrics
+	err = reader.Collect(context.Background(), &metrics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := map[string]bool{}
+	for _, scope := range metrics.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			found[m.Name] = true
+			if m.Name != "goforce.client.api_usage.used" {
+				continue
+			}
+			gauge, ok := m.Data.(metricdata.Gauge[int64])
+			if !ok || len(gauge.DataPoints) != 1 ||
+				gauge.DataPoints[0].Value != 25 {
+				t.Fatalf("unexpected api usage %+v", m.Data)
+			}
+		}
+	}
+	for _, name := range []string{
+		"goforce.client.request.duration",
+		"goforce.client.api_usage.used",
+		"goforce.client.api_usage.limit",
+	} {
+		if !found[name] {
+			t.Fatalf("expected metric %s", name)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package composite
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -99,17 +100,19 @@ func (req CompositeRequest) GetHeaders() (map[string]string, error) {
 		"Content-Type": "application/json",
 	}, nil
 }
+func (req CompositeRequest) GetVersion() string {
+	return req.Version
+}
 func (req CompositeRequest) GetPath(
 	version string,
 ) (*url.URL, error) {
-	v := req.Version
-	if len(v) == 0 {
-		v = version
+	v, err := Req.ResolveVersion(req.Version, version)
+	if err != nil {
+		return nil, err
 	}
-
 	ret, err := url.Parse(fmt.Sprintf(
 		"/services/data/v%s/composite",
-		version,
+		v,
 	))
 	if err != nil {
 		return nil, err
@@ -131,32 +134,27 @@ func Composite(
 	sfdcClient *client.Client,
 	request *CompositeRequest,
 ) (*CompositeResult, error) {
-	httpResponse, err := sfdcClient.Send(
+	return CompositeContext(
+		sfdcClient.GetContext(),
+		sfdcClient,
 		request,
 	)
-	if err != nil {
-		return nil, err
-	}
-	defer httpResponse.Body.Close()
-	if httpResponse.StatusCode >= 200 && httpResponse.StatusCode < 300 {
-		var ret CompositeResult
-		decodeError := json.NewDecoder(httpResponse.Body).Decode(&ret)
-
-		if decodeError != nil {
-			return nil, decodeError
-		}
+}
 
-		return &ret, nil
-	}
-	var errorResponse []Req.ApiError
-	decodeError := json.NewDecoder(httpResponse.Body).Decode(&errorResponse)
-	if decodeError != nil {
-		return nil, decodeError
-	}
-	if len(errorResponse) > 0 {
-		return nil, errorResponse[0]
+func CompositeContext(
+	ctx context.Context,
+	sfdcClient *client.Client,
+	request *CompositeRequest,
+) (*CompositeResult, error) {
+	res, err := Req.Do[CompositeResult](
+		ctx,
+		sfdcClient,
+		request,
+	)
+	if err != nil {
+		return nil, Req.WrapUnknown(err, ErrUnknown)
 	}
-	return nil, ErrUnknown
+	return res.Body, nil
 }
 
 var ErrUnknown = errors.New("unknown composite error")
@@ -0,0 +1,57 @@
+package composite
+
+import (
+	"errors"
+	"testing"
+
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+func TestCompositeRequestVersion(t *testing.T) {
+	tests := []struct {
+		override string
+		expected string
+		err      error
+	}{
+		{"", "/services/data/v60.0/composite", nil},
+		{"61.0", "/services/data/v61.0/composite", nil},
+		{"61", "/services/data/v61.0/composite", nil},
+		{"sixty", "", Req.ErrInvalidVersion},
+	}
+
+	for _, test := range tests {
+		req := CompositeRequest{Version: test.override}
+
+		path, err := req.GetPath("60.0")
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: expected %v, actual %v", test.override, test.err, err)
+			continue
+		}
+		if err == nil && path.String() != test.expected {
+			t.Errorf("%q: expected %v, actual %v", test.override, test.expected, path.String())
+		}
+	}
+}
+
+func TestSubRequestVersion(t *testing.T) {
+	subrequest, err := SubRequest(
+		CompositeRequest{Version: "61"},
+		&SubRequestOptions{Version: "60.0"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "/services/data/v61.0/composite"
+	if subrequest.Url != expected {
+		t.Errorf("expected %v, actual %v", expected, subrequest.Url)
+	}
+
+	_, err = SubRequest(
+		CompositeRequest{},
+		nil,
+	)
+	if !errors.Is(err, Req.ErrInvalidVersion) {
+		t.Errorf("expected %v, actual %v", Req.ErrInvalidVersion, err)
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/stackasaur/goforce/rest/limits
+
+go 1.24.2
+
+require (
+	github.com/stackasaur/goforce v0.1.0
+	github.com/stackasaur/goforce/client v0.1.1
+)
+
+require github.com/stackasaur/goforce/auth v0.1.0 // indirect
@@ -0,0 +1,142 @@
+package limits
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/stackasaur/goforce/client"
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+type LimitsRequest struct {
+	Version string
+}
+
+func (req LimitsRequest) GetMethod() (string, error) {
+	return http.MethodGet, nil
+}
+func (req LimitsRequest) GetHeaders() (map[string]string, error) {
+	return nil, nil
+}
+func (req LimitsRequest) GetVersion() string {
+	return req.Version
+}
+func (req LimitsRequest) GetPath(
+	version string,
+) (*url.URL, error) {
+	v, err := Req.ResolveVersion(req.Version, version)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := url.Parse(fmt.Sprintf(
+		"/services/data/v%s/limits",
+		v,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+func (req LimitsRequest) GetBody() ([]byte, error) {
+	return nil, nil
+}
+
+type Limit struct {
+	Max       int `json:"Max"`
+	Remaining int `json:"Remaining"`
+}
+
+func (limit Limit) Used() int {
+	return limit.Max - limit.Remaining
+}
+
+// LimitsResponse holds the commonly monitored limits as fields. every limit
+// returned by the org, including ones added in later api versions, is also
+// available in All keyed by name. per app breakdowns nested under some limits
+// are not decoded.
+type LimitsResponse struct {
+	ConcurrentAsyncGetReportInstances Limit
+	ConcurrentSyncReportRuns          Limit
+	DailyApiRequests                  Limit
+	DailyAsyncApexExecutions          Limit
+	DailyBulkApiBatches               Limit
+	DailyBulkV2QueryFileStorageMB     Limit
+	DailyBulkV2QueryJobs              Limit
+	DailyDurableStreamingApiEvents    Limit
+	DailyGenericStreamingApiEvents    Limit
+	DailyStreamingApiEvents           Limit
+	DailyWorkflowEmails               Limit
+	DataStorageMB                     Limit
+	FileStorageMB                     Limit
+	HourlyAsyncReportRuns             Limit
+	HourlyODataCallout                Limit
+	HourlyPublishedPlatformEvents     Limit
+	HourlySyncReportRuns              Limit
+	MassEmail                         Limit
+	PermissionSets                    Limit
+	SingleEmail                       Limit
+
+	All map[string]Limit `json:"-"`
+}
+
+func (res *LimitsResponse) UnmarshalJSON(
+	data []byte,
+) error {
+	// alias drops the method so the typed fields decode normally
+	type limitsResponse LimitsResponse
+	var typed limitsResponse
+	err := json.Unmarshal(data, &typed)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(data, &typed.All)
+	if err != nil {
+		return err
+	}
+
+	*res = LimitsResponse(typed)
+	return nil
+}
+
+// returns the named limit, false if the org did not report it.
+func (res *LimitsResponse) Get(
+	name string,
+) (Limit, bool) {
+	limit, ok := res.All[name]
+	return limit, ok
+}
+
+func Limits(
+	sfdcClient *client.Client,
+	request *LimitsRequest,
+) (*LimitsResponse, error) {
+	return LimitsContext(
+		sfdcClient.GetContext(),
+		sfdcClient,
+		request,
+	)
+}
+
+func LimitsContext(
+	ctx context.Context,
+	sfdcClient *client.Client,
+	request *LimitsRequest,
+) (*LimitsResponse, error) {
+	res, err := Req.Do[LimitsResponse](
+		ctx,
+		sfdcClient,
+		request,
+		http.StatusOK,
+	)
+	if err != nil {
+		return nil, Req.WrapUnknown(err, ErrUnknown)
+	}
+	return res.Body, nil
+}
+
+var ErrUnknown = errors.New("unknown limits error")
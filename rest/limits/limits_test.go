@@ -0,0 +1,151 @@
+package limits
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stackasaur/goforce/auth"
+	"github.com/stackasaur/goforce/client"
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+func TestLimitsRequest(t *testing.T) {
+	limitsRequest := LimitsRequest{}
+
+	actualUrl, err := limitsRequest.GetPath("60.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "/services/data/v60.0/limits"
+	actual := actualUrl.String()
+
+	if expected != actual {
+		t.Fatalf(
+			"expected %v, actual %v",
+			expected,
+			actual,
+		)
+	}
+}
+
+func TestLimitsRequestVersion(t *testing.T) {
+	tests := []struct {
+		override string
+		expected string
+		err      error
+	}{
+		{"", "/services/data/v60.0/limits", nil},
+		{"61.0", "/services/data/v61.0/limits", nil},
+		{"61", "/services/data/v61.0/limits", nil},
+		{"sixty", "", Req.ErrInvalidVersion},
+	}
+
+	for _, test := range tests {
+		limitsRequest := LimitsRequest{Version: test.override}
+
+		actualUrl, err := limitsRequest.GetPath("60.0")
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: expected %v, actual %v", test.override, test.err, err)
+			continue
+		}
+		if err == nil && actualUrl.String() != test.expected {
+			t.Errorf("%q: expected %v, actual %v", test.override, test.expected, actualUrl.String())
+		}
+	}
+}
+
+func TestLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/services/data/v60.0/limits" {
+				t.Errorf("unexpected path %s", r.URL.Path)
+			}
+			w.Write([]byte(`{
+				"DailyApiRequests": {
+					"Max": 15000,
+					"Remaining": 14998,
+					"Ant Migration Tool": {"Max": 0, "Remaining": 0}
+				},
+				"DataStorageMB": {"Max": 5, "Remaining": 5},
+				"DailyBulkV2QueryJobs": {"Max": 10000, "Remaining": 9990},
+				"SomeFutureLimit": {"Max": 10, "Remaining": 3}
+			}`))
+		},
+	))
+	defer server.Close()
+
+	sfdcClient, err := client.NewClient(
+		client.ClientConfig{
+			Version:    60,
+			HttpClient: server.Client(),
+			AuthFlow: &auth.SessionFlow{
+				AccessToken: "accessToken",
+				InstanceUrl: server.URL,
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Limits(
+		sfdcClient,
+		&LimitsRequest{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.DailyApiRequests.Used() != 2 ||
+		res.DailyBulkV2QueryJobs.Remaining != 9990 ||
+		res.DataStorageMB.Max != 5 {
+		t.Fatalf("unexpected limits %+v", res)
+	}
+	future, ok := res.Get("SomeFutureLimit")
+	if !ok || future.Remaining != 3 {
+		t.Fatalf("unexpected limit %+v", future)
+	}
+}
+
+func TestLimitsErrorPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`<html><body>maintenance</body></html>`))
+		},
+	))
+	defer server.Close()
+
+	sfdcClient, err := client.NewClient(
+		client.ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow: &auth.SessionFlow{
+				AccessToken: "accessToken",
+				InstanceUrl: server.URL,
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Limits(
+		sfdcClient,
+		&LimitsRequest{},
+	)
+	if !errors.Is(err, ErrUnknown) {
+		t.Errorf("expected %v, actual %v", ErrUnknown, err)
+	}
+	var responseError *Req.ResponseError
+	if !errors.As(err, &responseError) {
+		t.Fatalf("expected %T, actual %v", responseError, err)
+	}
+	if responseError.StatusCode != http.StatusServiceUnavailable ||
+		!strings.Contains(responseError.Body, "maintenance") {
+		t.Errorf("unexpected response error %+v", responseError)
+	}
+}
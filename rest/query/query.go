@@ -1,7 +1,7 @@
 package query
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -34,12 +34,15 @@ func (req QueryRequest) GetHeaders() (map[string]string, error) {
 	}
 	return ret, nil
 }
+func (req QueryRequest) GetVersion() string {
+	return req.Version
+}
 func (req QueryRequest) GetPath(
 	version string,
 ) (*url.URL, error) {
-	v := req.Version
-	if len(v) == 0 {
-		v = version
+	v, err := Req.ResolveVersion(req.Version, version)
+	if err != nil {
+		return nil, err
 	}
 	var queryPath string
 	if req.QueryOptions.QueryAll {
@@ -81,6 +84,18 @@ func (queryResponse *QueryResponse[T]) QueryMore(
 	sfdcClient *client.Client,
 	options QueryOptions,
 ) (*QueryResponse[T], error) {
+	return queryResponse.QueryMoreContext(
+		sfdcClient.GetContext(),
+		sfdcClient,
+		options,
+	)
+}
+
+func (queryResponse *QueryResponse[T]) QueryMoreContext(
+	ctx context.Context,
+	sfdcClient *client.Client,
+	options QueryOptions,
+) (*QueryResponse[T], error) {
 
 	if queryResponse.Done || len(queryResponse.NextRecordsUrl) == 0 {
 		return &QueryResponse[T]{
@@ -116,69 +131,46 @@ func (queryResponse *QueryResponse[T]) QueryMore(
 		Headers: headers,
 		Path:    path,
 	}
-	httpResponse, err := sfdcClient.Send(
+	res, err := Req.Do[QueryResponse[T]](
+		ctx,
+		sfdcClient,
 		req,
+		http.StatusOK,
 	)
 	if err != nil {
-		return nil, err
-	}
-	defer httpResponse.Body.Close()
-	if httpResponse.StatusCode == 200 {
-		var queryResponse QueryResponse[T]
-		decodeError := json.NewDecoder(httpResponse.Body).Decode(&queryResponse)
-
-		if decodeError != nil {
-			return nil, decodeError
-		}
-		queryResponse.QueryOptions = options
-		return &queryResponse, nil
-	}
-
-	var errorResponse []Req.ApiError
-	decodeError := json.NewDecoder(httpResponse.Body).Decode(&errorResponse)
-	if decodeError != nil {
-		return nil, decodeError
-	}
-	if len(errorResponse) > 0 {
-		return nil, errorResponse[0]
+		return nil, Req.WrapUnknown(err, ErrUnknown)
 	}
-	return nil, ErrUnknown
+	res.Body.QueryOptions = options
+	return res.Body, nil
 }
 
 func Query[T any](
 	sfdcClient *client.Client,
 	request *QueryRequest,
 ) (*QueryResponse[T], error) {
+	return QueryContext[T](
+		sfdcClient.GetContext(),
+		sfdcClient,
+		request,
+	)
+}
 
-	httpResponse, err := sfdcClient.Send(
+func QueryContext[T any](
+	ctx context.Context,
+	sfdcClient *client.Client,
+	request *QueryRequest,
+) (*QueryResponse[T], error) {
+	res, err := Req.Do[QueryResponse[T]](
+		ctx,
+		sfdcClient,
 		request,
+		http.StatusOK,
 	)
 	if err != nil {
-		return nil, err
+		return nil, Req.WrapUnknown(err, ErrUnknown)
 	}
-	defer httpResponse.Body.Close()
-	if httpResponse.StatusCode == 200 {
-		var queryResponse QueryResponse[T]
-		decodeError := json.NewDecoder(httpResponse.Body).Decode(&queryResponse)
-
-		if decodeError != nil {
-			return nil, decodeError
-		}
-
-		queryResponse.QueryOptions = request.QueryOptions
-		return &queryResponse, nil
-	}
-
-	var errorResponse []Req.ApiError
-	decodeError := json.NewDecoder(httpResponse.Body).Decode(&errorResponse)
-	if decodeError != nil {
-		return nil, decodeError
-	}
-	if len(errorResponse) > 0 {
-		return nil, errorResponse[0]
-	}
-	return nil, ErrUnknown
-
+	res.Body.QueryOptions = request.QueryOptions
+	return res.Body, nil
 }
 
 var ErrUnknown = errors.New("unknown query error")
@@ -44,6 +44,39 @@ func TestQueryRequest(t *testing.T) {
 	}
 }
 
+func TestQueryRequestVersion(t *testing.T) {
+	tests := []struct {
+		override string
+		queryAll bool
+		expected string
+		err      error
+	}{
+		{"", false, "/services/data/v60.0/query", nil},
+		{"61.0", false, "/services/data/v61.0/query", nil},
+		{"61", true, "/services/data/v61.0/queryAll", nil},
+		{"sixty", false, "", Req.ErrInvalidVersion},
+	}
+
+	for _, test := range tests {
+		queryRequest := QueryRequest{
+			Version: test.override,
+			Query:   "SELECT Id FROM Account",
+			QueryOptions: QueryOptions{
+				QueryAll: test.queryAll,
+			},
+		}
+
+		actualUrl, err := queryRequest.GetPath("60.0")
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: expected %v, actual %v", test.override, test.err, err)
+			continue
+		}
+		if err == nil && actualUrl.Path != test.expected {
+			t.Errorf("%q: expected %v, actual %v", test.override, test.expected, actualUrl.Path)
+		}
+	}
+}
+
 func TestQuery(t *testing.T) {
 	clientId := os.Getenv("CLIENT_ID")
 	clientSecret := os.Getenv("CLIENT_SECRET")
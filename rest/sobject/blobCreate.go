@@ -2,6 +2,7 @@ package sobject
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -41,12 +42,15 @@ func (req BlobCreateRequest) GetHeaders() (map[string]string, error) {
 		),
 	}, nil
 }
+func (req BlobCreateRequest) GetVersion() string {
+	return req.Version
+}
 func (req BlobCreateRequest) GetPath(
 	version string,
 ) (*url.URL, error) {
-	v := req.Version
-	if len(v) == 0 {
-		v = version
+	v, err := Req.ResolveVersion(req.Version, version)
+	if err != nil {
+		return nil, err
 	}
 	ret, err := url.Parse(fmt.Sprintf(
 		"/services/data/v%s/sobjects/%s",
@@ -127,34 +131,26 @@ func BlobCreate(
 	sfdcClient *client.Client,
 	request *BlobCreateRequest,
 ) (string, error) {
-	httpResponse, err := sfdcClient.Send(
+	return BlobCreateContext(
+		sfdcClient.GetContext(),
+		sfdcClient,
 		request,
 	)
-	if err != nil {
-		return "", err
-	}
-	defer httpResponse.Body.Close()
-	if httpResponse.StatusCode == 201 {
-		var ret SObjectResponse
-		decodeError := json.NewDecoder(httpResponse.Body).Decode(&ret)
-
-		if decodeError != nil {
-			return "", decodeError
-		}
+}
 
-		if ret.Success {
-			return ret.Id, nil
-		} else {
-			return "", ret.Errors[0]
-		}
-	}
-	var errorResponse []Req.ApiError
-	decodeError := json.NewDecoder(httpResponse.Body).Decode(&errorResponse)
-	if decodeError != nil {
-		return "", decodeError
-	}
-	if len(errorResponse) > 0 {
-		return "", errorResponse[0]
+func BlobCreateContext(
+	ctx context.Context,
+	sfdcClient *client.Client,
+	request *BlobCreateRequest,
+) (string, error) {
+	res, err := Req.Do[SObjectResponse](
+		ctx,
+		sfdcClient,
+		request,
+		http.StatusCreated,
+	)
+	if err != nil {
+		return "", Req.WrapUnknown(err, ErrUnknown)
 	}
-	return "", ErrUnknown
+	return res.Body.result()
 }
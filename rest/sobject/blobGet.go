@@ -1,9 +1,8 @@
 package sobject
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 
@@ -24,12 +23,15 @@ func (req BlobGetRequest) GetMethod() (string, error) {
 func (req BlobGetRequest) GetHeaders() (map[string]string, error) {
 	return nil, nil
 }
+func (req BlobGetRequest) GetVersion() string {
+	return req.Version
+}
 func (req BlobGetRequest) GetPath(
 	version string,
 ) (*url.URL, error) {
-	v := req.Version
-	if len(v) == 0 {
-		v = version
+	v, err := Req.ResolveVersion(req.Version, version)
+	if err != nil {
+		return nil, err
 	}
 	ret, err := url.Parse(fmt.Sprintf(
 		"/services/data/v%s/sobjects/%s/%s/%s",
@@ -58,32 +60,34 @@ func BlobGet(
 	sfdcClient *client.Client,
 	request *BlobGetRequest,
 ) (*Blob, error) {
-	httpResponse, err := sfdcClient.Send(
+	return BlobGetContext(
+		sfdcClient.GetContext(),
+		sfdcClient,
 		request,
 	)
-	if err != nil {
-		return nil, err
-	}
-	defer httpResponse.Body.Close()
-	if httpResponse.StatusCode == 200 {
-		blob := Blob{
-			ContentType: httpResponse.Header.Get("Content-Type"),
-		}
-		blob.Data, err = io.ReadAll(httpResponse.Body)
-		if err != nil {
-			return nil, err
-		}
-		blob.ContentLength = int64(len(blob.Data))
+}
 
-		return &blob, nil
+func BlobGetContext(
+	ctx context.Context,
+	sfdcClient *client.Client,
+	request *BlobGetRequest,
+) (*Blob, error) {
+	res, err := Req.Do[[]byte](
+		ctx,
+		sfdcClient,
+		request,
+		http.StatusOK,
+	)
+	if err != nil {
+		return nil, Req.WrapUnknown(err, ErrUnknown)
 	}
-	var errorResponse []Req.ApiError
-	decodeError := json.NewDecoder(httpResponse.Body).Decode(&errorResponse)
-	if decodeError != nil {
-		return nil, decodeError
+	blob := Blob{
+		ContentType: res.Header.Get("Content-Type"),
 	}
-	if len(errorResponse) > 0 {
-		return nil, errorResponse[0]
+	if res.Body != nil {
+		blob.Data = *res.Body
 	}
-	return nil, ErrUnknown
+	blob.ContentLength = int64(len(blob.Data))
+
+	return &blob, nil
 }
@@ -2,6 +2,7 @@ package sobject
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -36,12 +37,15 @@ func (req BlobUpdateRequest) GetHeaders() (map[string]string, error) {
 		),
 	}, nil
 }
+func (req BlobUpdateRequest) GetVersion() string {
+	return req.Version
+}
 func (req BlobUpdateRequest) GetPath(
 	version string,
 ) (*url.URL, error) {
-	v := req.Version
-	if len(v) == 0 {
-		v = version
+	v, err := Req.ResolveVersion(req.Version, version)
+	if err != nil {
+		return nil, err
 	}
 	ret, err := url.Parse(fmt.Sprintf(
 		"/services/data/v%s/sobjects/%s/%s",
@@ -128,23 +132,22 @@ func BlobUpdate(
 	sfdcClient *client.Client,
 	request *BlobUpdateRequest,
 ) error {
-	httpResponse, err := sfdcClient.Send(
+	return BlobUpdateContext(
+		sfdcClient.GetContext(),
+		sfdcClient,
 		request,
 	)
-	if err != nil {
-		return err
-	}
-	defer httpResponse.Body.Close()
-	if httpResponse.StatusCode >= 200 && httpResponse.StatusCode < 300 {
-		return nil
-	}
-	var errorResponse []Req.ApiError
-	decodeError := json.NewDecoder(httpResponse.Body).Decode(&errorResponse)
-	if decodeError != nil {
-		return decodeError
-	}
-	if len(errorResponse) > 0 {
-		return errorResponse[0]
-	}
-	return ErrUnknown
+}
+
+func BlobUpdateContext(
+	ctx context.Context,
+	sfdcClient *client.Client,
+	request *BlobUpdateRequest,
+) error {
+	_, err := Req.Do[struct{}](
+		ctx,
+		sfdcClient,
+		request,
+	)
+	return Req.WrapUnknown(err, ErrUnknown)
 }
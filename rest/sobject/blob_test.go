@@ -1,11 +1,15 @@
 package sobject
 
 import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
 	"github.com/stackasaur/goforce/auth"
 	"github.com/stackasaur/goforce/client"
+	Req "github.com/stackasaur/goforce/shared/request"
 )
 
 func TestBlobMethods(t *testing.T) {
@@ -135,3 +139,51 @@ func TestBlobMethods(t *testing.T) {
 		}
 	}
 }
+
+func TestBlobUpdateResponse(t *testing.T) {
+	statusCode := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(statusCode)
+			if statusCode >= 400 {
+				w.Write([]byte(`<html><body>error</body></html>`))
+			}
+		},
+	))
+	defer server.Close()
+
+	sfdcClient, err := client.NewClient(
+		client.ClientConfig{
+			HttpClient: server.Client(),
+			AuthFlow: &auth.SessionFlow{
+				AccessToken: "accessToken",
+				InstanceUrl: server.URL,
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := BlobUpdateRequest{
+		SObjectApiName: "ContentVersion",
+		RecordId:       "068000000000001AAA",
+		BinaryPartName: "VersionData",
+		BinaryData:     []byte("data"),
+		FileName:       "data.txt",
+	}
+
+	err = BlobUpdate(sfdcClient, &request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statusCode = http.StatusInternalServerError
+	err = BlobUpdate(sfdcClient, &request)
+	if !errors.Is(err, ErrUnknown) {
+		t.Errorf("expected %v, actual %v", ErrUnknown, err)
+	}
+	var responseError *Req.ResponseError
+	if !errors.As(err, &responseError) || responseError.StatusCode != statusCode {
+		t.Errorf("expected %v, actual %v", statusCode, err)
+	}
+}
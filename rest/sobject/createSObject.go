@@ -1,6 +1,7 @@
 package sobject
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -24,17 +25,19 @@ func (req CreateSObjectRequest) GetHeaders() (map[string]string, error) {
 		"Content-Type": "application/json",
 	}, nil
 }
+func (req CreateSObjectRequest) GetVersion() string {
+	return req.Version
+}
 func (req CreateSObjectRequest) GetPath(
 	version string,
 ) (*url.URL, error) {
-	v := req.Version
-	if len(v) == 0 {
-		v = version
+	v, err := Req.ResolveVersion(req.Version, version)
+	if err != nil {
+		return nil, err
 	}
-
 	ret, err := url.Parse(fmt.Sprintf(
 		"/services/data/v%s/sobjects/%s",
-		version,
+		v,
 		req.SObjectApiName,
 	))
 	if err != nil {
@@ -51,34 +54,26 @@ func CreateSObject(
 	sfdcClient *client.Client,
 	request *CreateSObjectRequest,
 ) (string, error) {
-	httpResponse, err := sfdcClient.Send(
+	return CreateSObjectContext(
+		sfdcClient.GetContext(),
+		sfdcClient,
 		request,
 	)
-	if err != nil {
-		return "", err
-	}
-	defer httpResponse.Body.Close()
-	if httpResponse.StatusCode == 201 {
-		var ret SObjectResponse
-		decodeError := json.NewDecoder(httpResponse.Body).Decode(&ret)
-
-		if decodeError != nil {
-			return "", decodeError
-		}
+}
 
-		if ret.Success {
-			return ret.Id, nil
-		} else {
-			return "", ret.Errors[0]
-		}
-	}
-	var errorResponse []Req.ApiError
-	decodeError := json.NewDecoder(httpResponse.Body).Decode(&errorResponse)
-	if decodeError != nil {
-		return "", decodeError
-	}
-	if len(errorResponse) > 0 {
-		return "", errorResponse[0]
+func CreateSObjectContext(
+	ctx context.Context,
+	sfdcClient *client.Client,
+	request *CreateSObjectRequest,
+) (string, error) {
+	res, err := Req.Do[SObjectResponse](
+		ctx,
+		sfdcClient,
+		request,
+		http.StatusCreated,
+	)
+	if err != nil {
+		return "", Req.WrapUnknown(err, ErrUnknown)
 	}
-	return "", ErrUnknown
+	return res.Body.result()
 }
@@ -1,7 +1,7 @@
 package sobject
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -45,14 +45,16 @@ func (req DeleteSObjectRequest) GetHeaders() (map[string]string, error) {
 	}
 	return headers, nil
 }
+func (req DeleteSObjectRequest) GetVersion() string {
+	return req.Version
+}
 func (req DeleteSObjectRequest) GetPath(
 	version string,
 ) (*url.URL, error) {
-	v := req.Version
-	if len(v) == 0 {
-		v = version
+	v, err := Req.ResolveVersion(req.Version, version)
+	if err != nil {
+		return nil, err
 	}
-
 	ret, err := url.Parse(fmt.Sprintf(
 		"/services/data/v%s/sobjects/%s/%s/",
 		v,
@@ -73,23 +75,23 @@ func DeleteSObject(
 	sfdcClient *client.Client,
 	request *DeleteSObjectRequest,
 ) error {
-	httpResponse, err := sfdcClient.Send(
+	return DeleteSObjectContext(
+		sfdcClient.GetContext(),
+		sfdcClient,
 		request,
 	)
-	if err != nil {
-		return err
-	}
-	defer httpResponse.Body.Close()
-	if httpResponse.StatusCode == 204 {
-		return nil
-	}
-	var errorResponse []Req.ApiError
-	decodeError := json.NewDecoder(httpResponse.Body).Decode(&errorResponse)
-	if decodeError != nil {
-		return decodeError
-	}
-	if len(errorResponse) > 0 {
-		return errorResponse[0]
-	}
-	return ErrUnknown
+}
+
+func DeleteSObjectContext(
+	ctx context.Context,
+	sfdcClient *client.Client,
+	request *DeleteSObjectRequest,
+) error {
+	_, err := Req.Do[struct{}](
+		ctx,
+		sfdcClient,
+		request,
+		http.StatusNoContent,
+	)
+	return Req.WrapUnknown(err, ErrUnknown)
 }
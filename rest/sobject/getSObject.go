@@ -1,7 +1,7 @@
 package sobject
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -46,12 +46,15 @@ func (req GetSObjectRequest) GetHeaders() (map[string]string, error) {
 	}
 	return headers, nil
 }
+func (req GetSObjectRequest) GetVersion() string {
+	return req.Version
+}
 func (req GetSObjectRequest) GetPath(
 	version string,
 ) (*url.URL, error) {
-	v := req.Version
-	if len(v) == 0 {
-		v = version
+	v, err := Req.ResolveVersion(req.Version, version)
+	if err != nil {
+		return nil, err
 	}
 	ret, err := url.Parse(fmt.Sprintf(
 		"/services/data/v%s/sobjects/%s/%s/",
@@ -81,30 +84,30 @@ func GetSObject[T any](
 	sfdcClient *client.Client,
 	request *GetSObjectRequest,
 ) (*T, error) {
-	httpResponse, err := sfdcClient.Send(
+	return GetSObjectContext[T](
+		sfdcClient.GetContext(),
+		sfdcClient,
 		request,
 	)
-	if err != nil {
-		return nil, err
-	}
-	defer httpResponse.Body.Close()
-	if httpResponse.StatusCode == 200 {
-		var ret T
-		decodeError := json.NewDecoder(httpResponse.Body).Decode(&ret)
-
-		if decodeError != nil {
-			return nil, decodeError
-		}
-		return &ret, nil
-	}
+}
 
-	var errorResponse []Req.ApiError
-	decodeError := json.NewDecoder(httpResponse.Body).Decode(&errorResponse)
-	if decodeError != nil {
-		return nil, decodeError
+func GetSObjectContext[T any](
+	ctx context.Context,
+	sfdcClient *client.Client,
+	request *GetSObjectRequest,
+) (*T, error) {
+	res, err := Req.Do[T](
+		ctx,
+		sfdcClient,
+		request,
+		http.StatusOK,
+		http.StatusNotModified,
+	)
+	if err != nil {
+		return nil, Req.WrapUnknown(err, ErrUnknown)
 	}
-	if len(errorResponse) > 0 {
-		return nil, errorResponse[0]
+	if res.StatusCode == http.StatusNotModified {
+		return nil, ErrNotModified
 	}
-	return nil, ErrUnknown
+	return res.Body, nil
 }
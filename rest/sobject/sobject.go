@@ -12,4 +12,24 @@ type SObjectResponse struct {
 	Success bool           `json:"success"`
 }
 
+// the id of a created record, or every error when creation failed. each error
+// can be matched with errors.Is, as on a Req.ResponseError.
+func (res *SObjectResponse) result() (string, error) {
+	if res.Success {
+		return res.Id, nil
+	}
+	if len(res.Errors) > 0 {
+		errs := make([]error, len(res.Errors))
+		for i, apiError := range res.Errors {
+			errs[i] = apiError
+		}
+		return "", errors.Join(errs...)
+	}
+	return "", ErrUnknown
+}
+
 var ErrUnknown = errors.New("unknown sobject error")
+
+// returned by GetSObject when a conditional request matched and the record
+// was not modified.
+var ErrNotModified = errors.New("sobject not modified")
@@ -1,6 +1,7 @@
 package sobject
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"testing"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/stackasaur/goforce/auth"
 	"github.com/stackasaur/goforce/client"
+	Req "github.com/stackasaur/goforce/shared/request"
 )
 
 type Account struct {
@@ -133,3 +135,25 @@ func TestSObjectFunctions(t *testing.T) {
 		)
 	}
 }
+
+func TestSObjectResponseResult(t *testing.T) {
+	res := SObjectResponse{
+		Errors: []Req.ApiError{
+			{ErrorCode: "INVALID_FIELD", Message: "No such column 'Foo'"},
+			{ErrorCode: "REQUIRED_FIELD_MISSING", Message: "Required fields are missing: [Name]"},
+		},
+	}
+
+	_, err := res.result()
+	if !errors.Is(err, Req.ErrInvalidField) {
+		t.Errorf("expected %v, actual %v", Req.ErrInvalidField, err)
+	}
+	if !errors.Is(err, Req.ApiError{ErrorCode: "REQUIRED_FIELD_MISSING"}) {
+		t.Errorf("expected %v, actual %v", "REQUIRED_FIELD_MISSING", err)
+	}
+
+	_, err = (&SObjectResponse{}).result()
+	if !errors.Is(err, ErrUnknown) {
+		t.Errorf("expected %v, actual %v", ErrUnknown, err)
+	}
+}
@@ -1,6 +1,7 @@
 package sobject
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -48,17 +49,19 @@ func (req UpdateSObjectRequest) GetHeaders() (map[string]string, error) {
 	}
 	return headers, nil
 }
+func (req UpdateSObjectRequest) GetVersion() string {
+	return req.Version
+}
 func (req UpdateSObjectRequest) GetPath(
 	version string,
 ) (*url.URL, error) {
-	v := req.Version
-	if len(v) == 0 {
-		v = version
+	v, err := Req.ResolveVersion(req.Version, version)
+	if err != nil {
+		return nil, err
 	}
-
 	ret, err := url.Parse(fmt.Sprintf(
 		"/services/data/v%s/sobjects/%s/%s/",
-		version,
+		v,
 		req.SObjectApiName,
 		req.RecordId,
 	))
@@ -76,23 +79,22 @@ func UpdateSObject(
 	sfdcClient *client.Client,
 	request *UpdateSObjectRequest,
 ) error {
-	httpResponse, err := sfdcClient.Send(
+	return UpdateSObjectContext(
+		sfdcClient.GetContext(),
+		sfdcClient,
 		request,
 	)
-	if err != nil {
-		return err
-	}
-	defer httpResponse.Body.Close()
-	if httpResponse.StatusCode >= 200 && httpResponse.StatusCode < 300 {
-		return nil
-	}
-	var errorResponse []Req.ApiError
-	decodeError := json.NewDecoder(httpResponse.Body).Decode(&errorResponse)
-	if decodeError != nil {
-		return decodeError
-	}
-	if len(errorResponse) > 0 {
-		return errorResponse[0]
-	}
-	return ErrUnknown
+}
+
+func UpdateSObjectContext(
+	ctx context.Context,
+	sfdcClient *client.Client,
+	request *UpdateSObjectRequest,
+) error {
+	_, err := Req.Do[struct{}](
+		ctx,
+		sfdcClient,
+		request,
+	)
+	return Req.WrapUnknown(err, ErrUnknown)
 }
@@ -0,0 +1,66 @@
+package sobject
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	Req "github.com/stackasaur/goforce/shared/request"
+)
+
+func TestRequestVersionOverride(t *testing.T) {
+	requests := map[string]func(version string) Req.SfdcRequest{
+		"CreateSObjectRequest": func(version string) Req.SfdcRequest {
+			return CreateSObjectRequest{Version: version, SObjectApiName: "Account"}
+		},
+		"GetSObjectRequest": func(version string) Req.SfdcRequest {
+			return GetSObjectRequest{Version: version, SObjectApiName: "Account", RecordId: "001"}
+		},
+		"UpdateSObjectRequest": func(version string) Req.SfdcRequest {
+			return UpdateSObjectRequest{Version: version, SObjectApiName: "Account", RecordId: "001"}
+		},
+		"DeleteSObjectRequest": func(version string) Req.SfdcRequest {
+			return DeleteSObjectRequest{Version: version, SObjectApiName: "Account", RecordId: "001"}
+		},
+		"BlobCreateRequest": func(version string) Req.SfdcRequest {
+			return BlobCreateRequest{Version: version, SObjectApiName: "ContentVersion"}
+		},
+		"BlobGetRequest": func(version string) Req.SfdcRequest {
+			return BlobGetRequest{Version: version, SObjectApiName: "ContentVersion", RecordId: "068", BlobField: "VersionData"}
+		},
+		"BlobUpdateRequest": func(version string) Req.SfdcRequest {
+			return BlobUpdateRequest{Version: version, SObjectApiName: "ContentVersion", RecordId: "068"}
+		},
+	}
+	tests := []struct {
+		override string
+		expected string
+		err      error
+	}{
+		{"", "/services/data/v60.0/", nil},
+		{"61.0", "/services/data/v61.0/", nil},
+		{"61", "/services/data/v61.0/", nil},
+		{"v61.0", "/services/data/v61.0/", nil},
+		{"sixty", "", Req.ErrInvalidVersion},
+	}
+
+	for name, newRequest := range requests {
+		for _, test := range tests {
+			req := newRequest(test.override)
+
+			versioned, ok := req.(Req.VersionedRequest)
+			if !ok || versioned.GetVersion() != test.override {
+				t.Errorf("%s: expected %v, actual %v", name, test.override, versioned)
+			}
+
+			path, err := req.GetPath("60.0")
+			if !errors.Is(err, test.err) {
+				t.Errorf("%s %q: expected %v, actual %v", name, test.override, test.err, err)
+				continue
+			}
+			if err == nil && !strings.HasPrefix(path.Path, test.expected) {
+				t.Errorf("%s %q: expected %v, actual %v", name, test.override, test.expected, path.Path)
+			}
+		}
+	}
+}
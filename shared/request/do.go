@@ -0,0 +1,104 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+)
+
+// sends requests to salesforce, implemented by client.Client.
+type Sender interface {
+	SendContext(
+		ctx context.Context,
+		req SfdcRequest,
+	) (*http.Response, error)
+}
+
+// a decoded response along with its metadata.
+type Response[T any] struct {
+	StatusCode int
+	Header     http.Header
+	// nil for 204 and 304 responses, an otherwise empty body decodes to the
+	// zero value.
+	Body *T
+}
+
+// sends req and decodes the response body into T. the response is a success
+// when its status is one of expectedStatuses, or any 2xx status when none are
+// given; any other status returns a *ResponseError. a *[]byte T receives the
+// raw body instead of decoding json.
+func Do[T any](
+	ctx context.Context,
+	sender Sender,
+	req SfdcRequest,
+	expectedStatuses ...int,
+) (*Response[T], error) {
+	httpResponse, err := sender.SendContext(
+		ctx,
+		req,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResponse.Body.Close()
+
+	if !isExpectedStatus(httpResponse.StatusCode, expectedStatuses) {
+		return nil, NewResponseError(httpResponse)
+	}
+
+	response := Response[T]{
+		StatusCode: httpResponse.StatusCode,
+		Header:     httpResponse.Header,
+	}
+	if httpResponse.StatusCode == http.StatusNoContent ||
+		httpResponse.StatusCode == http.StatusNotModified {
+		return &response, nil
+	}
+
+	var body T
+	switch ret := any(&body).(type) {
+	case *[]byte:
+		*ret, err = io.ReadAll(httpResponse.Body)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		err = json.NewDecoder(httpResponse.Body).Decode(ret)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+	}
+	response.Body = &body
+
+	return &response, nil
+}
+
+func isExpectedStatus(
+	statusCode int,
+	expectedStatuses []int,
+) bool {
+	if len(expectedStatuses) == 0 {
+		return statusCode >= 200 && statusCode < 300
+	}
+	return slices.Contains(expectedStatuses, statusCode)
+}
+
+// joins unknown onto a *ResponseError without any ApiError, such as an html
+// error page, so packages can keep reporting their own sentinel for failures
+// salesforce did not describe.
+func WrapUnknown(
+	err error,
+	unknown error,
+) error {
+	var responseError *ResponseError
+	if errors.As(err, &responseError) && len(responseError.Errors) == 0 {
+		return errors.Join(
+			unknown,
+			err,
+		)
+	}
+	return err
+}
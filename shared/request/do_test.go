@@ -0,0 +1,131 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type staticSender struct {
+	statusCode int
+	body       string
+}
+
+func (sender staticSender) SendContext(
+	_ context.Context,
+	_ SfdcRequest,
+) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: sender.statusCode,
+		Header:     http.Header{"Etag": []string{`"etag"`}},
+		Body:       io.NopCloser(strings.NewReader(sender.body)),
+	}, nil
+}
+
+type record struct {
+	Id string
+}
+
+func TestDo(t *testing.T) {
+	res, err := Do[record](
+		context.Background(),
+		staticSender{statusCode: http.StatusOK, body: `{"Id":"001"}`},
+		GenericRequest{},
+		http.StatusOK,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Body == nil || res.Body.Id != "001" {
+		t.Errorf("expected %v, actual %v", "001", res.Body)
+	}
+	if res.Header.Get("ETag") != `"etag"` {
+		t.Errorf("expected %v, actual %v", `"etag"`, res.Header.Get("ETag"))
+	}
+}
+
+func TestDoNoContent(t *testing.T) {
+	for _, statusCode := range []int{http.StatusNoContent, http.StatusNotModified} {
+		res, err := Do[record](
+			context.Background(),
+			staticSender{statusCode: statusCode},
+			GenericRequest{},
+			http.StatusOK,
+			http.StatusNoContent,
+			http.StatusNotModified,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != statusCode || res.Body != nil {
+			t.Errorf("expected %v, actual %v", statusCode, res)
+		}
+	}
+}
+
+func TestDoRawBody(t *testing.T) {
+	res, err := Do[[]byte](
+		context.Background(),
+		staticSender{statusCode: http.StatusOK, body: "not json"},
+		GenericRequest{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(*res.Body) != "not json" {
+		t.Errorf("expected %v, actual %v", "not json", string(*res.Body))
+	}
+}
+
+func TestDoUnexpectedStatus(t *testing.T) {
+	_, err := Do[record](
+		context.Background(),
+		staticSender{statusCode: http.StatusCreated, body: `{"Id":"001"}`},
+		GenericRequest{},
+		http.StatusOK,
+	)
+	var responseError *ResponseError
+	if !errors.As(err, &responseError) || responseError.StatusCode != http.StatusCreated {
+		t.Errorf("expected %v, actual %v", http.StatusCreated, err)
+	}
+
+	_, err = Do[record](
+		context.Background(),
+		staticSender{
+			statusCode: http.StatusBadRequest,
+			body:       `[{"errorCode":"INVALID_FIELD","message":"bad"}]`,
+		},
+		GenericRequest{},
+	)
+	if !errors.Is(err, ErrInvalidField) {
+		t.Errorf("expected %v, actual %v", ErrInvalidField, err)
+	}
+}
+
+func TestWrapUnknown(t *testing.T) {
+	errUnknown := errors.New("unknown")
+
+	_, err := Do[record](
+		context.Background(),
+		staticSender{statusCode: http.StatusBadGateway, body: "<html></html>"},
+		GenericRequest{},
+	)
+	if !errors.Is(WrapUnknown(err, errUnknown), errUnknown) {
+		t.Errorf("expected %v, actual %v", errUnknown, err)
+	}
+
+	_, err = Do[record](
+		context.Background(),
+		staticSender{
+			statusCode: http.StatusNotFound,
+			body:       `[{"errorCode":"NOT_FOUND","message":"missing"}]`,
+		},
+		GenericRequest{},
+	)
+	if errors.Is(WrapUnknown(err, errUnknown), errUnknown) {
+		t.Errorf("expected %v, actual %v", err, WrapUnknown(err, errUnknown))
+	}
+}
@@ -0,0 +1,146 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// error returned for a non successful response from a salesforce api. it
+// unwraps to every ApiError in the body so both
+//
+//	errors.Is(err, request.ErrEntityIsDeleted)
+//
+// and errors.As with an ApiError or a *ResponseError work.
+type ResponseError struct {
+	StatusCode int
+	Method     string
+	Path       string
+	// value of the X-SFDC-Request-Id header, useful when raising a case
+	// with salesforce support.
+	RequestId string
+	Header    http.Header
+	// all errors in the response body, empty when the body was not a
+	// salesforce error response, such as an html error page.
+	Errors []ApiError
+	// the start of the response body, see MaxBodyExcerpt.
+	Body string
+}
+
+// number of bytes of the response body kept on a ResponseError.
+const MaxBodyExcerpt int = 1024
+
+// largest error body read when building a ResponseError.
+const maxErrorBody int64 = 1 << 20
+
+// reads the body of a failed response into a ResponseError. the body is
+// consumed but not closed.
+func NewResponseError(
+	httpResponse *http.Response,
+) *ResponseError {
+	responseError := ResponseError{
+		StatusCode: httpResponse.StatusCode,
+		RequestId:  httpResponse.Header.Get("X-SFDC-Request-Id"),
+		Header:     httpResponse.Header,
+	}
+	if httpResponse.Request != nil {
+		responseError.Method = httpResponse.Request.Method
+		responseError.Path = httpResponse.Request.URL.Path
+	}
+	if httpResponse.Body == nil {
+		return &responseError
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(httpResponse.Body, maxErrorBody))
+	responseError.Errors = parseApiErrors(body)
+	if len(body) > MaxBodyExcerpt {
+		body = body[:MaxBodyExcerpt]
+	}
+	responseError.Body = string(body)
+
+	return &responseError
+}
+
+// salesforce usually returns a list of errors but some endpoints return a
+// single one.
+func parseApiErrors(
+	body []byte,
+) []ApiError {
+	var apiErrors []ApiError
+	if json.Unmarshal(body, &apiErrors) == nil {
+		return apiErrors
+	}
+	var apiError ApiError
+	if json.Unmarshal(body, &apiError) == nil && len(apiError.ErrorCode) > 0 {
+		return []ApiError{apiError}
+	}
+	return nil
+}
+
+func (e *ResponseError) Error() string {
+	var builder strings.Builder
+	fmt.Fprintf(
+		&builder,
+		"%s %s: %d %s",
+		e.Method,
+		e.Path,
+		e.StatusCode,
+		http.StatusText(e.StatusCode),
+	)
+	for _, apiError := range e.Errors {
+		builder.WriteString(": ")
+		builder.WriteString(apiError.Error())
+	}
+	if len(e.Errors) == 0 && len(e.Body) > 0 {
+		builder.WriteString(": ")
+		builder.WriteString(e.Body)
+	}
+	return builder.String()
+}
+
+func (e *ResponseError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, apiError := range e.Errors {
+		errs[i] = apiError
+	}
+	return errs
+}
+
+// true when the response contains an error with the given code.
+func (e *ResponseError) HasErrorCode(
+	errorCode string,
+) bool {
+	for _, apiError := range e.Errors {
+		if apiError.ErrorCode == errorCode {
+			return true
+		}
+	}
+	return false
+}
+
+// matches an ApiError with the same ErrorCode, so the values below can be
+// used with errors.Is.
+func (r ApiError) Is(
+	target error,
+) bool {
+	apiError, ok := target.(ApiError)
+	return ok && apiError.ErrorCode == r.ErrorCode
+}
+
+// common error codes, compare with errors.Is.
+var ErrEntityIsDeleted = ApiError{ErrorCode: "ENTITY_IS_DELETED", Message: "entity is deleted"}
+var ErrInvalidField = ApiError{ErrorCode: "INVALID_FIELD", Message: "invalid field"}
+var ErrInvalidType = ApiError{ErrorCode: "INVALID_TYPE", Message: "invalid type"}
+var ErrNotFound = ApiError{ErrorCode: "NOT_FOUND", Message: "not found"}
+var ErrMalformedId = ApiError{ErrorCode: "MALFORMED_ID", Message: "malformed id"}
+var ErrMalformedQuery = ApiError{ErrorCode: "MALFORMED_QUERY", Message: "malformed query"}
+var ErrInvalidQueryLocator = ApiError{ErrorCode: "INVALID_QUERY_LOCATOR", Message: "invalid query locator"}
+var ErrInvalidSessionId = ApiError{ErrorCode: "INVALID_SESSION_ID", Message: "invalid session id"}
+var ErrRequiredFieldMissing = ApiError{ErrorCode: "REQUIRED_FIELD_MISSING", Message: "required field missing"}
+var ErrDuplicateValue = ApiError{ErrorCode: "DUPLICATE_VALUE", Message: "duplicate value"}
+var ErrFieldCustomValidation = ApiError{ErrorCode: "FIELD_CUSTOM_VALIDATION_EXCEPTION", Message: "validation rule failed"}
+var ErrInsufficientAccess = ApiError{ErrorCode: "INSUFFICIENT_ACCESS_OR_READONLY", Message: "insufficient access"}
+var ErrUnableToLockRow = ApiError{ErrorCode: "UNABLE_TO_LOCK_ROW", Message: "unable to lock row"}
+var ErrRequestLimitExceeded = ApiError{ErrorCode: "REQUEST_LIMIT_EXCEEDED", Message: "request limit exceeded"}
@@ -0,0 +1,96 @@
+package request
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newErrorResponse(
+	statusCode int,
+	body string,
+) *http.Response {
+	path, _ := url.Parse("https://example.com/services/data/v60.0/sobjects/Account/001")
+	return &http.Response{
+		StatusCode: statusCode,
+		Header: http.Header{
+			"X-Sfdc-Request-Id": []string{"requestId"},
+		},
+		Body: io.NopCloser(strings.NewReader(body)),
+		Request: &http.Request{
+			Method: http.MethodDelete,
+			URL:    path,
+		},
+	}
+}
+
+func TestResponseError(t *testing.T) {
+	responseError := NewResponseError(newErrorResponse(
+		http.StatusNotFound,
+		`[
+			{"errorCode":"ENTITY_IS_DELETED","message":"entity is deleted"},
+			{"errorCode":"INVALID_FIELD","message":"No such column","fields":["Foo__c"]}
+		]`,
+	))
+	var err error = responseError
+
+	if responseError.StatusCode != http.StatusNotFound ||
+		responseError.Method != http.MethodDelete ||
+		responseError.Path != "/services/data/v60.0/sobjects/Account/001" ||
+		responseError.RequestId != "requestId" ||
+		len(responseError.Errors) != 2 {
+		t.Fatalf("unexpected response error %+v", responseError)
+	}
+
+	if !errors.Is(err, ErrEntityIsDeleted) {
+		t.Errorf("expected %v, actual %v", ErrEntityIsDeleted, err)
+	}
+	if !errors.Is(err, ErrInvalidField) {
+		t.Errorf("expected %v, actual %v", ErrInvalidField, err)
+	}
+	if errors.Is(err, ErrMalformedQuery) {
+		t.Errorf("expected not %v, actual %v", ErrMalformedQuery, err)
+	}
+
+	var apiError ApiError
+	if !errors.As(err, &apiError) || apiError.ErrorCode != "ENTITY_IS_DELETED" {
+		t.Errorf("expected %v, actual %v", "ENTITY_IS_DELETED", apiError.ErrorCode)
+	}
+	var asResponseError *ResponseError
+	joined := errors.Join(errors.New("wrapped"), err)
+	if !errors.As(joined, &asResponseError) || asResponseError != responseError {
+		t.Errorf("expected %v, actual %v", responseError, asResponseError)
+	}
+}
+
+func TestResponseErrorNonJson(t *testing.T) {
+	body := "<html>" + strings.Repeat("x", 2*MaxBodyExcerpt) + "</html>"
+	responseError := NewResponseError(newErrorResponse(
+		http.StatusInternalServerError,
+		body,
+	))
+
+	if len(responseError.Errors) != 0 {
+		t.Errorf("expected %v, actual %v", 0, len(responseError.Errors))
+	}
+	if len(responseError.Body) != MaxBodyExcerpt {
+		t.Errorf("expected %v, actual %v", MaxBodyExcerpt, len(responseError.Body))
+	}
+	if !strings.HasPrefix(responseError.Error(), "DELETE /services/data/v60.0/sobjects/Account/001: 500 Internal Server Error: <html>") {
+		t.Errorf("unexpected error %v", responseError.Error())
+	}
+}
+
+func TestResponseErrorSingleObject(t *testing.T) {
+	responseError := NewResponseError(newErrorResponse(
+		http.StatusBadRequest,
+		`{"errorCode":"MALFORMED_ID","message":"bad id"}`,
+	))
+
+	if !errors.Is(responseError, ErrMalformedId) {
+		t.Errorf("expected %v, actual %v", ErrMalformedId, responseError)
+	}
+}
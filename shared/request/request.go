@@ -2,6 +2,7 @@ package request
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -45,6 +46,22 @@ func SfdcRequestAsHttpRequest(
 	sfdcReq SfdcRequest,
 	baseUrl *url.URL,
 	version string,
+) (*http.Request, error) {
+	return SfdcRequestAsHttpRequestWithContext(
+		context.Background(),
+		sfdcReq,
+		baseUrl,
+		version,
+	)
+}
+
+// same as SfdcRequestAsHttpRequest, the returned request carries ctx so
+// cancelling it aborts the call.
+func SfdcRequestAsHttpRequestWithContext(
+	ctx context.Context,
+	sfdcReq SfdcRequest,
+	baseUrl *url.URL,
+	version string,
 ) (*http.Request, error) {
 	bodyBytes, err := sfdcReq.GetBody()
 	if err != nil {
@@ -84,7 +101,8 @@ func SfdcRequestAsHttpRequest(
 	}
 
 	endpoint := baseUrl.ResolveReference(path)
-	ret, err := http.NewRequest(
+	ret, err := http.NewRequestWithContext(
+		ctx,
 		method,
 		endpoint.String(),
 		bytes.NewReader(
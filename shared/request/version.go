@@ -0,0 +1,48 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// implemented by requests with a Version field overriding the client's
+// version. the client validates the override before sending.
+type VersionedRequest interface {
+	GetVersion() string
+}
+
+// parses a version such as "60.0", "v60.0" or "60" into its major number.
+func ParseVersion(
+	version string,
+) (int, error) {
+	trimmed := strings.TrimPrefix(version, "v")
+	major, minor, _ := strings.Cut(trimmed, ".")
+	number, err := strconv.Atoi(major)
+	if err != nil || number <= 0 || (len(minor) > 0 && minor != "0") {
+		return 0, errors.Join(
+			ErrInvalidVersion,
+			fmt.Errorf("unrecognized version %q", version),
+		)
+	}
+	return number, nil
+}
+
+// the version used in a request path: override when set, otherwise the
+// client's version. either is normalized to the "60.0" form.
+func ResolveVersion(
+	override string,
+	version string,
+) (string, error) {
+	if len(override) > 0 {
+		version = override
+	}
+	number, err := ParseVersion(version)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d.0", number), nil
+}
+
+var ErrInvalidVersion = errors.New("invalid version")
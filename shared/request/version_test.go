@@ -0,0 +1,63 @@
+package request
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected int
+		valid    bool
+	}{
+		{"60.0", 60, true},
+		{"v61.0", 61, true},
+		{"62", 62, true},
+		{"60.1", 0, false},
+		{"abc", 0, false},
+		{"", 0, false},
+		{"-1.0", 0, false},
+	}
+
+	for _, test := range tests {
+		actual, err := ParseVersion(test.version)
+		if (err == nil) != test.valid || actual != test.expected {
+			t.Errorf(
+				"%q: expected %v, actual %v (%v)",
+				test.version,
+				test.expected,
+				actual,
+				err,
+			)
+		}
+	}
+}
+
+func TestResolveVersion(t *testing.T) {
+	tests := []struct {
+		override string
+		version  string
+		expected string
+		err      error
+	}{
+		{"", "60.0", "60.0", nil},
+		{"61.0", "60.0", "61.0", nil},
+		{"61", "60.0", "61.0", nil},
+		{"v61.0", "60.0", "61.0", nil},
+		{"sixty", "60.0", "", ErrInvalidVersion},
+	}
+
+	for _, test := range tests {
+		actual, err := ResolveVersion(test.override, test.version)
+		if actual != test.expected || !errors.Is(err, test.err) {
+			t.Errorf(
+				"%q: expected %v, actual %v (%v)",
+				test.override,
+				test.expected,
+				actual,
+				err,
+			)
+		}
+	}
+}